@@ -22,7 +22,8 @@ CREATE TABLE Dim_Tiempo (
     NumeroSemana INT,
     EsFinDeSemana BIT,
     EsFeriado BIT DEFAULT 0,
-    TrimestreAnio NVARCHAR(10)
+    TrimestreAnio NVARCHAR(10),
+    NombreFeriado NVARCHAR(50) NOT NULL DEFAULT ''
 );
 
 CREATE TABLE Dim_Producto (
@@ -172,6 +173,39 @@ CREATE TABLE Fact_MetricasWeb (
     CONSTRAINT FK_MetricasWeb_Canal FOREIGN KEY (IDCanal) REFERENCES Dim_CanalVenta(IDCanal)
 );
 
+-- =======================
+-- CONTROL DEL GENERADOR
+-- =======================
+
+-- Perfil y configuración de cada corrida de 02_Generacion_Datos.go
+CREATE TABLE Control_Ejecucion (
+    IDEjecucion BIGINT IDENTITY(1,1) PRIMARY KEY,
+    Perfil NVARCHAR(100) NOT NULL,
+    ConfigJSON NVARCHAR(MAX) NOT NULL,
+    FechaInicio DATETIME2 NOT NULL,
+    FechaFin DATETIME2 NULL,
+    Estado NVARCHAR(20) NOT NULL
+);
+
+-- Un punto de control por commit de la carga, para generate -resume
+CREATE TABLE Control_Checkpoint (
+    HashConfig NVARCHAR(64) NOT NULL,
+    Tabla NVARCHAR(50) NOT NULL,
+    Bloque INT NOT NULL,
+    UltimoNumeroPedido NVARCHAR(20) NULL,
+    EstadoRNG NVARCHAR(100) NULL,
+    Filas INT NOT NULL,
+    Huella NVARCHAR(1000) NULL,
+    FechaConfirmacion DATETIME2 NOT NULL,
+    PRIMARY KEY (HashConfig, Tabla, Bloque)
+);
+
+-- Marca de destino protegido (ver entornos.yaml)
+CREATE TABLE Control_Destino (
+    Nombre NVARCHAR(100) NOT NULL PRIMARY KEY,
+    FechaMarca DATETIME2 NOT NULL
+);
+
 -- =======================
 -- ÍNDICES OPTIMIZADOS
 -- =======================
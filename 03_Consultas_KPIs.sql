@@ -11,6 +11,14 @@ PRINT 'ANÁLISIS DE KPIs ESTRATÉGICOS - CÁRNICOS DEL CARIBE';
 PRINT '=====================================================';
 PRINT '';
 
+-- Fecha de corte: la fecha ancla del dataset (último día de Dim_Tiempo), no la
+-- fecha del servidor. El año de análisis es el último año completo.
+DECLARE @FechaCorte DATE = (SELECT MAX(Fecha) FROM Dim_Tiempo);
+DECLARE @AnioAnalisis INT = YEAR(DATEADD(day, 1, @FechaCorte)) - 1;
+
+PRINT 'Fecha de corte: ' + CONVERT(VARCHAR(10), @FechaCorte, 23) + ' | Año de análisis: ' + CAST(@AnioAnalisis AS VARCHAR(4));
+PRINT '';
+
 -- =========================================================
 -- 1. KPI CRECIMIENTO VENTAS VS PRESUPUESTO
 -- =========================================================
@@ -33,7 +41,7 @@ SELECT
     END as Estado
 FROM Fact_Ventas fv
 JOIN Dim_Tiempo dt ON fv.IDTiempoVenta = dt.IDTiempo
-WHERE dt.Anio = 2024
+WHERE dt.Anio = @AnioAnalisis
 GROUP BY YEAR(dt.Fecha), MONTH(dt.Fecha)
 ORDER BY Año, Mes;
 
@@ -88,7 +96,7 @@ WITH VentasAnioActual AS (
         COUNT(DISTINCT fv.NumeroPedido) as TicketPromedio
     FROM Fact_Ventas fv
     JOIN Dim_Tiempo dt ON fv.IDTiempoVenta = dt.IDTiempo
-    WHERE dt.Anio = 2024
+    WHERE dt.Anio = @AnioAnalisis
     GROUP BY MONTH(dt.Fecha)
 ),
 VentasAnioAnterior AS (
@@ -98,7 +106,7 @@ VentasAnioAnterior AS (
         COUNT(DISTINCT fv.NumeroPedido) as TicketPromedioAnterior
     FROM Fact_Ventas fv
     JOIN Dim_Tiempo dt ON fv.IDTiempoVenta = dt.IDTiempo
-    WHERE dt.Anio = 2023
+    WHERE dt.Anio = @AnioAnalisis - 1
     GROUP BY MONTH(dt.Fecha)
 )
 SELECT 
@@ -264,7 +272,7 @@ SELECT
 FROM Fact_Finanzas ff
 JOIN Dim_Sucursal ds ON ff.IDSucursal = ds.IDSucursal
 JOIN Dim_Tiempo dt ON ff.IDTiempo = dt.IDTiempo
-WHERE dt.Anio = 2024
+WHERE dt.Anio = @AnioAnalisis
 ORDER BY ds.NombreSucursal, dt.Anio, dt.Trimestre;
 
 PRINT '';
@@ -415,7 +423,7 @@ Retencion AS (
     GROUP BY ca1.Anio
 )
 SELECT * FROM Retencion
-WHERE Anio < YEAR(GETDATE())
+WHERE Anio < YEAR(@FechaCorte)
 ORDER BY Anio;
 
 PRINT '';
@@ -0,0 +1,588 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"kpi-generator-go/generador"
+)
+
+// ================== SUBCOMANDOS ==================
+type comando struct {
+	nombre      string
+	descripcion string
+	ejecutar    func(ctx context.Context, args []string) error
+}
+
+var comandos = []comando{
+	{"generate", "Limpia las tablas y genera el data warehouse completo", cmdGenerate},
+	{"clean", "Elimina los datos de todas las tablas del modelo", cmdClean},
+	{"validate", "Ejecuta las validaciones de 04_Validacion_Datos.sql", cmdValidate},
+	{"kpis", "Ejecuta las consultas de 03_Consultas_KPIs.sql", cmdKPIs},
+	{"schema", "Crea el esquema estrella (01_Esquema_Estrella.sql)", cmdSchema},
+	{"bench", "Compara los métodos de carga de Fact_Ventas (insert, bulk, tvp)", cmdBench},
+}
+
+// errUso marca una invocación mal formada (subcomando desconocido); main
+// termina con código 2 en lugar de 1.
+var errUso = errors.New("uso incorrecto")
+
+// ejecutarCLI corre el subcomando de args; ctx se cancela con SIGINT/SIGTERM.
+// Una invocación mal formada devuelve un error que envuelve errUso.
+func ejecutarCLI(ctx context.Context, args []string) error {
+	// Sin subcomando se mantiene el comportamiento histórico: limpiar y generar todo
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "--help" {
+		return cmdGenerate(ctx, args)
+	}
+
+	switch args[0] {
+	case "help", "-h", "--help":
+		uso()
+		return nil
+	}
+
+	for _, c := range comandos {
+		if c.nombre == args[0] {
+			return c.ejecutar(ctx, args[1:])
+		}
+	}
+
+	uso()
+	return fmt.Errorf("%w: subcomando desconocido %q", errUso, args[0])
+}
+
+func uso() {
+	fmt.Fprintln(os.Stderr, "Uso: kpi-generator-go <subcomando> [opciones]")
+	fmt.Fprintln(os.Stderr, "\nSubcomandos:")
+	for _, c := range comandos {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.nombre, c.descripcion)
+	}
+	fmt.Fprintln(os.Stderr, "\nUse \"kpi-generator-go <subcomando> -h\" para ver las opciones de cada uno.")
+}
+
+// registrarFlagsConfig expone cada campo de Config como flag; el valor por
+// defecto es el que tenga cfg al momento del registro.
+func registrarFlagsConfig(fs *flag.FlagSet, cfg *generador.Config) {
+	fs.IntVar(&cfg.VentasRecords, "ventas-records", cfg.VentasRecords, "Registros de Fact_Ventas")
+	fs.IntVar(&cfg.FinanzasYears, "finanzas-years", cfg.FinanzasYears, "Años de registros mensuales en Fact_Finanzas")
+	fs.IntVar(&cfg.SatisfaccionRecords, "satisfaccion-records", cfg.SatisfaccionRecords, "Encuestas en Fact_SatisfaccionCliente")
+	fs.IntVar(&cfg.MetricasWebMonths, "metricas-web-months", cfg.MetricasWebMonths, "Meses de Fact_MetricasWeb")
+	fs.IntVar(&cfg.DimProductos, "dim-productos", cfg.DimProductos, "Registros de Dim_Producto")
+	fs.IntVar(&cfg.DimClientes, "dim-clientes", cfg.DimClientes, "Registros de Dim_Cliente")
+	fs.IntVar(&cfg.DimSucursales, "dim-sucursales", cfg.DimSucursales, "Registros de Dim_Sucursal")
+	fs.IntVar(&cfg.DimEmpleados, "dim-empleados", cfg.DimEmpleados, "Registros de Dim_Empleado")
+	fs.IntVar(&cfg.DimTiempoAnios, "dim-tiempo-anios", cfg.DimTiempoAnios, "Años cubiertos por Dim_Tiempo")
+	fs.IntVar(&cfg.VentasWorkers, "ventas-workers", cfg.VentasWorkers, "Workers de Fact_Ventas, cada uno con su conexión (0 = uno por CPU)")
+	fs.IntVar(&cfg.MaxConexiones, "max-connections", cfg.MaxConexiones, "Conexiones abiertas a la vez contra la base (0 = sin tope)")
+	fs.IntVar(&cfg.Reintentos, "retries", cfg.Reintentos, "Reintentos por transacción ante errores transitorios (throttling, failover, deadlock)")
+	fs.IntVar(&cfg.BatchSize, "batch-size", cfg.BatchSize, "Tope de filas por lote (0 = el máximo que admita cada tabla)")
+	fs.Float64Var(&cfg.ScaleFactor, "scale-factor", cfg.ScaleFactor, "Factor de escala de los volúmenes (por defecto SCALE_FACTOR)")
+	fs.Int64Var(&cfg.Seed, "seed", cfg.Seed, "Semilla de la generación (0 = aleatoria)")
+	fs.Var(&cfg.FechaInicio, "fecha-inicio", "Primer día del dataset, AAAA-MM-DD (por defecto fecha-fin - dim-tiempo-anios)")
+	fs.Var(&cfg.FechaFin, "fecha-fin", "Fecha ancla del dataset, AAAA-MM-DD (por defecto hoy)")
+	fs.Var(&cfg.ModosCarga, "load-mode", "Método de carga por tabla: insert, bulk o tvp (\"Fact_Ventas=bulk,...\" o \"bulk\" para todas)")
+}
+
+// flagsConexion son los flags con que un subcomando elige la base.
+type flagsConexion struct {
+	motor   *string
+	destino *string
+}
+
+// registrarFlagDialecto agrega -dialect y -target a un subcomando que se
+// conecta a la base.
+func registrarFlagDialecto(fs *flag.FlagSet) flagsConexion {
+	predeterminado := os.Getenv("DB_DIALECT")
+	if predeterminado == "" {
+		predeterminado = generador.DialectoSQLServer
+	}
+	return flagsConexion{
+		motor:   fs.String("dialect", predeterminado, "Motor destino: sqlserver, postgres o sqlite (por defecto DB_DIALECT)"),
+		destino: fs.String("target", "", "Destino con nombre de entornos.yaml; sus variables y su dialecto reemplazan a los del .env"),
+	}
+}
+
+// dialecto aplica el destino de -target, si lo hay, y devuelve su dialecto
+// o, si no declara uno, el de -dialect. Se llama una vez por subcomando.
+func (c flagsConexion) dialecto() (string, error) {
+	if *c.destino == "" {
+		return *c.motor, nil
+	}
+	e, err := generador.UsarEntorno(*c.destino)
+	if err != nil {
+		return "", err
+	}
+	if e.Dialecto != "" {
+		return e.Dialecto, nil
+	}
+	return *c.motor, nil
+}
+
+// confirmacionLimpieza son los flags con que se autoriza limpiar un destino
+// protegido.
+type confirmacionLimpieza struct {
+	destino *string
+	ajenos  *bool
+}
+
+// registrarFlagConfirmacion agrega -confirm y -discard-foreign a un
+// subcomando que limpia la base.
+func registrarFlagConfirmacion(fs *flag.FlagSet) confirmacionLimpieza {
+	return confirmacionLimpieza{
+		destino: fs.String("confirm", "", "Nombre del destino protegido que se autoriza a limpiar"),
+		ajenos: fs.Bool("discard-foreign", false,
+			"Con -confirm, limpia aunque haya datos que no cargó el generador (p. ej. anteriores a Control_Checkpoint)"),
+	}
+}
+
+// limpiarModelo vacía las tablas del modelo si AutorizarLimpieza lo permite.
+func limpiarModelo(ctx context.Context, r *generador.Corrida, db *sql.DB, confirmacion confirmacionLimpieza) error {
+	if err := r.AutorizarLimpieza(ctx, db, *confirmacion.destino, *confirmacion.ajenos); err != nil {
+		return err
+	}
+	log.Println("\n🧹 Limpiando tablas existentes...")
+	return r.LimpiarTablas(ctx, db)
+}
+
+func nuevoFlagSet(nombre, descripcion string) *flag.FlagSet {
+	fs := flag.NewFlagSet(nombre, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Uso: kpi-generator-go %s [opciones]\n%s\n\nOpciones:\n", nombre, descripcion)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parsearFlags interpreta args; un flag inválido es un error de uso y -h
+// devuelve flag.ErrHelp tal cual, después de mostrar la ayuda.
+func parsearFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return fmt.Errorf("%w: %v", errUso, err)
+	}
+	return err
+}
+
+// ================== GENERATE ==================
+const salidaSQL = "sql"
+
+func cmdGenerate(ctx context.Context, args []string) error {
+	fs := nuevoFlagSet("generate", "Limpia las tablas y genera el data warehouse completo.")
+	cfg := generador.ConfigPredeterminada()
+	registrarFlagsConfig(fs, &cfg)
+	perfil := fs.String("profile", "", "Perfil de configuración (nombre en perfiles/ o ruta a .yaml/.json)")
+	sinLimpieza := fs.Bool("no-clean", false, "No limpiar las tablas antes de generar")
+	confirmacion := registrarFlagConfirmacion(fs)
+	reanudar := fs.Bool("resume", false, "Reanudar la última ejecución sin completar desde sus puntos de control")
+	simulacion := fs.Bool("dry-run", false, "Mostrar el plan de carga por tabla sin tocar la base de datos")
+	salida := fs.String("output", salidaSQL, "Destino de las filas: sql, csv o parquet")
+	dirSalida := fs.String("output-dir", "salida", "Directorio de los archivos con -output csv/parquet")
+	modoProgreso := fs.String("progress", generador.ProgresoAuto, "Progreso por tabla: auto (tty en terminal, json si no), tty, json u off")
+	conexion := registrarFlagDialecto(fs)
+	if err := parsearFlags(fs, args); err != nil {
+		return err
+	}
+	motor, err := conexion.dialecto()
+	if err != nil {
+		return err
+	}
+
+	if err := resolverConfig(fs, *perfil, &cfg); err != nil {
+		return err
+	}
+	if err := generador.ValidarConfig(cfg); err != nil {
+		return fmt.Errorf("configuración inválida:\n%w", err)
+	}
+	r, err := generador.NuevaCorrida(cfg, motor)
+	if err != nil {
+		return err
+	}
+
+	if *reanudar && (*simulacion || *salida != salidaSQL) {
+		return fmt.Errorf("-resume solo aplica a cargas en la base de datos: no se combina con -dry-run ni con -output %s/%s",
+			generador.SalidaCSV, generador.SalidaParquet)
+	}
+	if *simulacion {
+		return planificarCarga(ctx, r)
+	}
+	if err := r.UsarProgreso(*modoProgreso); err != nil {
+		return fmt.Errorf("-progress: %w", err)
+	}
+
+	switch *salida {
+	case salidaSQL:
+		if err := r.ModosSoportados(); err != nil {
+			return fmt.Errorf("-load-mode: %w", err)
+		}
+	case generador.SalidaCSV, generador.SalidaParquet:
+		return exportarArchivos(ctx, r, *salida, *dirSalida)
+	default:
+		return fmt.Errorf("-output inválido %q (use %s, %s o %s)", *salida,
+			salidaSQL, generador.SalidaCSV, generador.SalidaParquet)
+	}
+
+	db, err := r.Conectar(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if *reanudar {
+		return reanudarCarga(ctx, r, db)
+	}
+	if !*sinLimpieza {
+		if err := limpiarModelo(ctx, r, db, confirmacion); err != nil {
+			return err
+		}
+	}
+
+	idEjecucion, err := r.RegistrarInicioEjecucion(ctx, db)
+	if err != nil {
+		return err
+	}
+	return cargarEnBase(ctx, r, db, idEjecucion, generador.NuevoDestinoSQL(db))
+}
+
+// cargarEnBase genera el modelo en dest con la corrida r y cierra la
+// ejecución idEjecucion como completada, fallida o, si se canceló ctx,
+// interrumpida.
+func cargarEnBase(ctx context.Context, r *generador.Corrida, db *sql.DB, idEjecucion int64, dest generador.Destino) error {
+	err := r.Generar(ctx, dest)
+	if err == nil {
+		return r.RegistrarFinEjecucion(ctx, db, idEjecucion, generador.EstadoCompletada)
+	}
+
+	// El cierre se registra aunque ctx esté cancelado; el error que se
+	// informa es el de la carga
+	estado := generador.EstadoFallida
+	if ctx.Err() != nil {
+		estado = generador.EstadoInterrumpida
+		err = fmt.Errorf("carga interrumpida: %w", err)
+	}
+	if errFin := r.RegistrarFinEjecucion(context.WithoutCancel(ctx), db, idEjecucion, estado); errFin != nil {
+		log.Printf("⚠️  %v", errFin)
+	}
+	log.Printf("💾 Lo confirmado quedó en Control_Checkpoint; continúe con: generate -resume")
+	return err
+}
+
+// reanudarCarga retoma la última ejecución sin completar. Los datos salen de
+// la configuración registrada en Control_Ejecucion (semilla, volúmenes,
+// fechas); de la invocación actual (r) solo se toman los parámetros
+// operativos.
+func reanudarCarga(ctx context.Context, r *generador.Corrida, db *sql.DB) error {
+	idEjecucion, cfg, err := r.EjecucionPendiente(ctx, db)
+	if err != nil {
+		return err
+	}
+	actual := r.Config()
+	cfg.VentasWorkers = actual.VentasWorkers
+	cfg.MaxConexiones = actual.MaxConexiones
+	cfg.BatchSize = actual.BatchSize
+	cfg.Reintentos = actual.Reintentos
+	cfg.ModosCarga = actual.ModosCarga
+	if err := generador.ValidarConfig(cfg); err != nil {
+		return fmt.Errorf("configuración de la ejecución #%d inválida:\n%w", idEjecucion, err)
+	}
+	reanudada := r.ConConfig(cfg)
+
+	log.Printf("\n♻️  Reanudando ejecución #%d (semilla %d, %s .. %s)",
+		idEjecucion, cfg.Seed, cfg.FechaInicio, cfg.FechaFin)
+	dest, err := reanudada.ReanudarDestinoSQL(ctx, db)
+	if err != nil {
+		return err
+	}
+	return cargarEnBase(ctx, reanudada, db, idEjecucion, dest)
+}
+
+// planificarCarga recorre los mismos generadores que una carga real pero
+// solo contabiliza filas, sentencias y commits por tabla.
+func planificarCarga(ctx context.Context, r *generador.Corrida) error {
+	log.Println("🧪 Modo -dry-run: no se abrirá conexión a la base de datos")
+	plan := generador.NuevoPlanCarga()
+
+	salida := log.Writer()
+	log.SetOutput(io.Discard)
+	inicio := time.Now()
+	err := r.Generar(ctx, plan)
+	duracion := time.Since(inicio)
+	log.SetOutput(salida)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	plan.Imprimir(os.Stdout, duracion)
+	return nil
+}
+
+// exportarArchivos genera el modelo completo en archivos planos, sin
+// conexión a la base de datos ni registro en Control_Ejecucion.
+func exportarArchivos(ctx context.Context, r *generador.Corrida, formato, dir string) error {
+	dest, err := generador.NuevoDestinoArchivos(dir, formato)
+	if err != nil {
+		return err
+	}
+	log.Printf("📂 Exportando a %s en %s/", formato, dir)
+	if err := r.Generar(ctx, dest); err != nil {
+		return err
+	}
+	log.Printf("✅ Archivos %s escritos en %s/", formato, dir)
+	return nil
+}
+
+// resolverConfig arma la configuración final con la precedencia
+// valores compilados < perfil < SCALE_FACTOR < flags explícitos.
+// Los volúmenes pasados por flag son absolutos y no se escalan.
+func resolverConfig(fs *flag.FlagSet, perfil string, cfg *generador.Config) error {
+	explicitos := map[string]string{}
+	fs.Visit(func(f *flag.Flag) { explicitos[f.Name] = f.Value.String() })
+	delete(explicitos, "profile")
+
+	if perfil != "" {
+		p, err := generador.CargarPerfil(perfil)
+		if err != nil {
+			return err
+		}
+		*cfg = p
+		log.Printf("📁 Perfil cargado: %s", cfg.Perfil)
+	}
+	if cfg.ScaleFactor == 0 {
+		cfg.ScaleFactor = 1
+	}
+
+	if v := os.Getenv("SCALE_FACTOR"); v != "" {
+		factor, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("SCALE_FACTOR inválido %q: %w", v, err)
+		}
+		cfg.ScaleFactor = factor
+	}
+	if v, ok := explicitos["scale-factor"]; ok {
+		fs.Set("scale-factor", v)
+	}
+	*cfg = generador.EscalarConfig(*cfg)
+
+	for flagNombre, valor := range explicitos {
+		fs.Set(flagNombre, valor)
+	}
+	if perfil != "" && len(explicitos) > 0 {
+		cfg.Perfil += "+flags"
+	}
+	if cfg.ScaleFactor != 1 {
+		log.Printf("📐 Factor de escala: %g", cfg.ScaleFactor)
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	generador.ResolverFechas(cfg)
+	log.Printf("📅 Rango del dataset: %s .. %s", cfg.FechaInicio, cfg.FechaFin)
+	return nil
+}
+
+// ================== BENCH ==================
+// cmdBench vacía el modelo y delega en Corrida.CompararMetodos, que carga
+// las dimensiones una vez y Fact_Ventas con cada método.
+func cmdBench(ctx context.Context, args []string) error {
+	fs := nuevoFlagSet("bench", "Compara los métodos de carga de Fact_Ventas. Vacía las tablas del modelo;\n"+
+		"al terminar Fact_Ventas queda con la carga del último método y los demás hechos vacíos.")
+	cfg := generador.ConfigPredeterminada()
+	registrarFlagsConfig(fs, &cfg)
+	perfil := fs.String("profile", "", "Perfil de configuración (nombre en perfiles/ o ruta a .yaml/.json)")
+	metodos := fs.String("methods", strings.Join(generador.ModosValidos(), ","), "Métodos a comparar, separados por coma")
+	confirmacion := registrarFlagConfirmacion(fs)
+	conexion := registrarFlagDialecto(fs)
+	if err := parsearFlags(fs, args); err != nil {
+		return err
+	}
+	motor, err := conexion.dialecto()
+	if err != nil {
+		return err
+	}
+
+	if err := resolverConfig(fs, *perfil, &cfg); err != nil {
+		return err
+	}
+	if err := generador.ValidarConfig(cfg); err != nil {
+		return fmt.Errorf("configuración inválida:\n%w", err)
+	}
+	r, err := generador.NuevaCorrida(cfg, motor)
+	if err != nil {
+		return err
+	}
+	if err := r.ModosSoportados(); err != nil {
+		return fmt.Errorf("-load-mode: %w", err)
+	}
+
+	var comparar []string
+	for _, m := range strings.Split(*metodos, ",") {
+		comparar = append(comparar, strings.ToLower(strings.TrimSpace(m)))
+	}
+
+	db, err := r.Conectar(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := limpiarModelo(ctx, r, db, confirmacion); err != nil {
+		return err
+	}
+	if err := r.CompararMetodos(ctx, db, comparar, os.Stdout); err != nil {
+		return fmt.Errorf("bench: %w", err)
+	}
+	return nil
+}
+
+// ================== CLEAN ==================
+func cmdClean(ctx context.Context, args []string) error {
+	fs := nuevoFlagSet("clean", "Elimina los datos de todas las tablas del modelo.")
+	confirmacion := registrarFlagConfirmacion(fs)
+	conexion := registrarFlagDialecto(fs)
+	if err := parsearFlags(fs, args); err != nil {
+		return err
+	}
+	motor, err := conexion.dialecto()
+	if err != nil {
+		return err
+	}
+
+	r, db, err := conectarDialecto(ctx, motor)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return limpiarModelo(ctx, r, db, confirmacion)
+}
+
+// ================== VALIDATE ==================
+func cmdValidate(ctx context.Context, args []string) error {
+	fs := nuevoFlagSet("validate", "Ejecuta las validaciones de 04_Validacion_Datos.sql.")
+	conexion := registrarFlagDialecto(fs)
+	if err := parsearFlags(fs, args); err != nil {
+		return err
+	}
+	motor, err := conexion.dialecto()
+	if err != nil {
+		return err
+	}
+	if err := exigirTSQL(motor, "04_Validacion_Datos.sql", "use test-suite para validar en "+motor); err != nil {
+		return err
+	}
+
+	r, db, err := conectarDialecto(ctx, motor)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := r.EjecutarScript(ctx, db, "04_Validacion_Datos.sql", scriptValidacion); err != nil {
+		return fmt.Errorf("ejecutando validaciones: %w", err)
+	}
+	return nil
+}
+
+// ================== KPIS ==================
+func cmdKPIs(ctx context.Context, args []string) error {
+	fs := nuevoFlagSet("kpis", "Ejecuta las consultas de 03_Consultas_KPIs.sql.")
+	conexion := registrarFlagDialecto(fs)
+	if err := parsearFlags(fs, args); err != nil {
+		return err
+	}
+	motor, err := conexion.dialecto()
+	if err != nil {
+		return err
+	}
+	if err := exigirTSQL(motor, "03_Consultas_KPIs.sql", "los KPIs están escritos en T-SQL"); err != nil {
+		return err
+	}
+
+	r, db, err := conectarDialecto(ctx, motor)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := r.EjecutarScript(ctx, db, "03_Consultas_KPIs.sql", scriptKPIs); err != nil {
+		return fmt.Errorf("ejecutando KPIs: %w", err)
+	}
+	return nil
+}
+
+// ================== SCHEMA ==================
+func cmdSchema(ctx context.Context, args []string) error {
+	fs := nuevoFlagSet("schema", "Crea el esquema estrella (01_Esquema_Estrella.sql).")
+	conIndices := fs.Bool("indices", false, "Ejecutar también 05_Crear_Indices.sql")
+	conexion := registrarFlagDialecto(fs)
+	if err := parsearFlags(fs, args); err != nil {
+		return err
+	}
+	motor, err := conexion.dialecto()
+	if err != nil {
+		return err
+	}
+	if *conIndices {
+		if err := exigirTSQL(motor, "05_Crear_Indices.sql", "01_Esquema_Estrella.sql ya crea los índices básicos"); err != nil {
+			return err
+		}
+	}
+
+	r, db, err := conectarDialecto(ctx, motor)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	esquema := r.TraducirDDL(scriptEsquema)
+	if err := r.EjecutarScript(ctx, db, "01_Esquema_Estrella.sql", esquema); err != nil {
+		return fmt.Errorf("creando esquema: %w", err)
+	}
+	if *conIndices {
+		if err := r.EjecutarScript(ctx, db, "05_Crear_Indices.sql", scriptIndices); err != nil {
+			return fmt.Errorf("creando índices: %w", err)
+		}
+	}
+	return nil
+}
+
+// corridaSinGenerar arma la corrida de los subcomandos que no generan datos:
+// solo usan el dialecto y el tope de conexiones predeterminado.
+func corridaSinGenerar(motor string) (*generador.Corrida, error) {
+	return generador.NuevaCorrida(generador.ConfigPredeterminada(), motor)
+}
+
+// conectarDialecto arma la corrida del motor pedido y abre su conexión.
+func conectarDialecto(ctx context.Context, motor string) (*generador.Corrida, *sql.DB, error) {
+	r, err := corridaSinGenerar(motor)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := r.Conectar(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, db, nil
+}
+
+// exigirTSQL falla si el script solo existe en T-SQL y el motor pedido no es
+// SQL Server.
+func exigirTSQL(motor, script, sugerencia string) error {
+	r, err := corridaSinGenerar(motor)
+	if err != nil {
+		return err
+	}
+	if d := r.Dialecto(); d != generador.DialectoSQLServer {
+		return fmt.Errorf("%s es T-SQL y no está disponible en %s (%s)", script, d, sugerencia)
+	}
+	return nil
+}
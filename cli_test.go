@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"maps"
+	"testing"
+
+	"kpi-generator-go/generador"
+)
+
+// resolverFlags arma el FlagSet de generate con args, con SCALE_FACTOR=escala
+// ("" = sin definir) y el log silenciado.
+func resolverFlags(t *testing.T, escala string, args ...string) (*flag.FlagSet, *generador.Config, string) {
+	t.Helper()
+	t.Setenv("SCALE_FACTOR", escala)
+	salida := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(salida) })
+
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	cfg := generador.ConfigPredeterminada()
+	registrarFlagsConfig(fs, &cfg)
+	perfil := fs.String("profile", "", "")
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return fs, &cfg, *perfil
+}
+
+// resolver corre resolverConfig como lo hace "generate args".
+func resolver(t *testing.T, escala string, args ...string) generador.Config {
+	t.Helper()
+	fs, cfg, perfil := resolverFlags(t, escala, args...)
+	if err := resolverConfig(fs, perfil, cfg); err != nil {
+		t.Fatalf("resolverConfig(%q): %v", args, err)
+	}
+	return *cfg
+}
+
+func TestResolverConfigPredeterminada(t *testing.T) {
+	cfg := resolver(t, "", "-fecha-fin", "2026-06-30")
+	base := generador.ConfigPredeterminada()
+	if cfg.VentasRecords != base.VentasRecords || cfg.DimClientes != base.DimClientes {
+		t.Errorf("sin perfil ni escala los volúmenes cambiaron: %+v", cfg)
+	}
+	if cfg.Perfil != base.Perfil {
+		t.Errorf("Perfil = %q; se esperaba %q", cfg.Perfil, base.Perfil)
+	}
+	if cfg.Seed == 0 {
+		t.Error("sin -seed debe elegirse una semilla")
+	}
+	if got := cfg.FechaInicio.String(); got != "2023-06-30" {
+		t.Errorf("FechaInicio = %s; se esperaba fecha-fin - dim-tiempo-anios (2023-06-30)", got)
+	}
+}
+
+func TestResolverConfigPerfil(t *testing.T) {
+	cfg := resolver(t, "", "-profile", "1M")
+	if cfg.Perfil != "1M" {
+		t.Errorf("Perfil = %q; se esperaba 1M", cfg.Perfil)
+	}
+	if cfg.VentasRecords != 894_083 || cfg.Seed != 20251031 || cfg.FechaFin.String() != "2025-10-31" {
+		t.Errorf("no se tomaron los valores del perfil: %+v", cfg)
+	}
+}
+
+// Precedencia: valores compilados < perfil < SCALE_FACTOR < flags explícitos.
+func TestResolverConfigPrecedencia(t *testing.T) {
+	casos := []struct {
+		nombre string
+		escala string
+		args   []string
+		perfil string
+		ventas int
+		sucurs int
+		client int
+	}{
+		{"SCALE_FACTOR escala el perfil", "0.1", []string{"-profile", "1M"}, "1M", 89_408, 20, 5_000},
+		{"-scale-factor gana a SCALE_FACTOR", "0.5", []string{"-profile", "1M", "-scale-factor", "0.1"},
+			"1M+flags", 89_408, 20, 5_000},
+		{"los volúmenes por flag no se escalan", "", []string{"-profile", "1M", "-scale-factor", "0.1",
+			"-ventas-records", "1234", "-dim-sucursales", "7"}, "1M+flags", 1_234, 7, 5_000},
+		{"flags sin perfil", "", []string{"-dim-clientes", "300"}, "predeterminado", 894_083, 20, 300},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			cfg := resolver(t, c.escala, c.args...)
+			if cfg.Perfil != c.perfil {
+				t.Errorf("Perfil = %q; se esperaba %q", cfg.Perfil, c.perfil)
+			}
+			if cfg.VentasRecords != c.ventas || cfg.DimSucursales != c.sucurs || cfg.DimClientes != c.client {
+				t.Errorf("ventas/sucursales/clientes = %d/%d/%d; se esperaba %d/%d/%d",
+					cfg.VentasRecords, cfg.DimSucursales, cfg.DimClientes, c.ventas, c.sucurs, c.client)
+			}
+		})
+	}
+}
+
+func TestResolverConfigFlagsOperativosSobrePerfil(t *testing.T) {
+	cfg := resolver(t, "", "-profile", "1M", "-ventas-workers", "2", "-load-mode", "Fact_Ventas=bulk", "-seed", "9")
+	if cfg.VentasWorkers != 2 || cfg.Seed != 9 {
+		t.Errorf("ventas-workers/seed = %d/%d; se esperaba 2/9", cfg.VentasWorkers, cfg.Seed)
+	}
+	if esperados := (generador.ModosCarga{"Fact_Ventas": "bulk"}); !maps.Equal(cfg.ModosCarga, esperados) {
+		t.Errorf("ModosCarga = %v; se esperaba %v", cfg.ModosCarga, esperados)
+	}
+	// El resto sigue siendo del perfil
+	if cfg.MaxConexiones != 8 || cfg.FechaFin.String() != "2025-10-31" {
+		t.Errorf("se perdieron valores del perfil: %+v", cfg)
+	}
+}
+
+func TestResolverConfigErrores(t *testing.T) {
+	fs, cfg, perfil := resolverFlags(t, "mucho")
+	if err := resolverConfig(fs, perfil, cfg); err == nil {
+		t.Error("se aceptó SCALE_FACTOR=mucho")
+	}
+	fs, cfg, perfil = resolverFlags(t, "", "-profile", "no-existe")
+	if err := resolverConfig(fs, perfil, cfg); err == nil {
+		t.Error("se aceptó un perfil inexistente")
+	}
+}
+
+// Un subcomando o un flag desconocido es un error de uso (código 2), no una
+// falla de la carga; -h no es un error.
+func TestEjecutarCLIErrorDeUso(t *testing.T) {
+	for _, args := range [][]string{{"generar"}, {"clean", "-no-existe"}} {
+		if err := ejecutarCLI(context.Background(), args); !errors.Is(err, errUso) {
+			t.Errorf("ejecutarCLI(%q) = %v; se esperaba un error de uso", args, err)
+		}
+	}
+	if err := ejecutarCLI(context.Background(), []string{"schema", "-h"}); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("ejecutarCLI(schema -h) = %v; se esperaba flag.ErrHelp", err)
+	}
+}
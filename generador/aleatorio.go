@@ -0,0 +1,64 @@
+package generador
+
+import (
+	"fmt"
+	"hash/fnv"
+	mathrand "math/rand"
+	"math/rand/v2"
+
+	"github.com/go-faker/faker/v4"
+)
+
+// ================== FUENTES ALEATORIAS DETERMINISTAS ==================
+// nuevoRNG devuelve un flujo PCG independiente para cada tabla, derivado de la
+// semilla de la corrida y del nombre de la tabla. Así la misma semilla produce
+// los mismos datos sin importar el orden en que se ejecuten las goroutines.
+func nuevoRNG(seed int64, tabla string) *rand.Rand {
+	return rand.New(nuevaFuente(seed, tabla))
+}
+
+// nuevaFuente es el PCG detrás de nuevoRNG, para quien necesite leer su
+// estado (los puntos de control de Fact_Ventas).
+func nuevaFuente(seed int64, tabla string) *rand.PCG {
+	h := fnv.New64a()
+	h.Write([]byte(tabla))
+	return rand.NewPCG(uint64(seed), h.Sum64())
+}
+
+// ================== VOCABULARIO DE NOMBRES ==================
+// faker usa una fuente global compartida, así que los nombres se generan una
+// sola vez y en secuencia antes de lanzar goroutines; después cada tabla
+// combina el vocabulario con su propio flujo aleatorio.
+type vocabularioNombres struct {
+	nombres   []string
+	apellidos []string
+}
+
+const (
+	nombresPorGenero = 150
+	totalApellidos   = 300
+)
+
+func nuevoVocabularioNombres(seed int64) *vocabularioNombres {
+	faker.SetRandomSource(mathrand.NewSource(seed))
+
+	v := &vocabularioNombres{
+		nombres:   make([]string, 0, nombresPorGenero*2),
+		apellidos: make([]string, 0, totalApellidos),
+	}
+	for i := 0; i < nombresPorGenero; i++ {
+		v.nombres = append(v.nombres, faker.FirstNameMale(), faker.FirstNameFemale())
+	}
+	for i := 0; i < totalApellidos; i++ {
+		v.apellidos = append(v.apellidos, faker.LastName())
+	}
+	return v
+}
+
+// aleatorio arma un nombre completo con dos apellidos.
+func (v *vocabularioNombres) aleatorio(rng *rand.Rand) string {
+	return fmt.Sprintf("%s %s %s",
+		v.nombres[rng.IntN(len(v.nombres))],
+		v.apellidos[rng.IntN(len(v.apellidos))],
+		v.apellidos[rng.IntN(len(v.apellidos))])
+}
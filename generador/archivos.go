@@ -0,0 +1,140 @@
+package generador
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ================== DESTINO ARCHIVOS ==================
+// Formatos de archivo de NuevoDestinoArchivos.
+const (
+	SalidaCSV     = "csv"
+	SalidaParquet = "parquet"
+)
+
+// destinoArchivos escribe un archivo <tabla>.<formato> por tabla en dir, con
+// las mismas columnas que carga insertBatchTx.
+type destinoArchivos struct {
+	dir     string
+	formato string
+}
+
+// NuevoDestinoArchivos escribe un archivo por tabla en dir con el formato
+// SalidaCSV o SalidaParquet.
+func NuevoDestinoArchivos(dir, formato string) (Destino, error) {
+	if formato != SalidaCSV && formato != SalidaParquet {
+		return nil, fmt.Errorf("formato de salida inválido %q (use %s o %s)", formato, SalidaCSV, SalidaParquet)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creando directorio de salida %s: %w", dir, err)
+	}
+	return &destinoArchivos{dir: dir, formato: formato}, nil
+}
+
+// Un archivo por tabla: no admite escritores concurrentes sobre la misma.
+func (d *destinoArchivos) particionable() bool { return false }
+
+// La exportación siempre escribe todas las tablas.
+func (d *destinoArchivos) confirmado(string, int) bool { return false }
+
+func (d *destinoArchivos) abrir(_ context.Context, _ *Corrida, tabla tablaSpec) (sink, error) {
+	ruta := filepath.Join(d.dir, tabla.nombre+"."+d.formato)
+	if d.formato == SalidaParquet {
+		return abrirParquet(ruta, tabla)
+	}
+	return abrirCSV(ruta, tabla)
+}
+
+// ================== CSV ==================
+// Un archivo por tabla con encabezado; pensado para cargar el modelo estrella
+// en Power BI sin acceso a SQL Server.
+func abrirCSV(ruta string, tabla tablaSpec) (sink, error) {
+	f, err := os.Create(ruta)
+	if err != nil {
+		return nil, err
+	}
+	s := &sinkCSV{ruta: ruta, archivo: f, buf: bufio.NewWriterSize(f, 1<<20)}
+	s.csv = csv.NewWriter(s.buf)
+	if err := s.csv.Write(tabla.nombresColumnas()); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+type sinkCSV struct {
+	ruta    string
+	archivo *os.File
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	campos  []string
+}
+
+func (s *sinkCSV) metodo() string { return SalidaCSV }
+
+func (s *sinkCSV) escribir(filas [][]interface{}) error {
+	for _, fila := range filas {
+		s.campos = s.campos[:0]
+		for _, v := range fila {
+			s.campos = append(s.campos, formatearCampoCSV(v))
+		}
+		if err := s.csv.Write(s.campos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sinkCSV) confirmar() error {
+	s.csv.Flush()
+	if err := s.csv.Error(); err != nil {
+		return err
+	}
+	return s.buf.Flush()
+}
+
+func (s *sinkCSV) cerrar() error {
+	if err := s.confirmar(); err != nil {
+		s.archivo.Close()
+		return err
+	}
+	err := s.archivo.Close()
+	s.archivo = nil
+	return err
+}
+
+// descartar cierra el archivo sin completar y lo elimina, para no dejar una
+// tabla a medias que parezca válida.
+func (s *sinkCSV) descartar() {
+	if s.archivo != nil {
+		s.archivo.Close()
+		s.archivo = nil
+		os.Remove(s.ruta)
+	}
+}
+
+// formatearCampoCSV usa formatos que Power BI reconoce sin configuración:
+// fechas ISO, decimales con punto y dos cifras, booleanos true/false.
+func formatearCampoCSV(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', 2, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		return x.Format(formatoFecha)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
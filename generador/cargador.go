@@ -0,0 +1,574 @@
+package generador
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ================== DESTINOS DE LA GENERACIÓN ==================
+// Destino indica a dónde van las filas generadas: la base de datos, archivos
+// planos o, en modo -dry-run, un plan que solo las contabiliza. Los
+// generadores son los mismos en todos los casos. Se obtiene con
+// NuevoDestinoSQL, NuevoDestinoArchivos o NuevoPlanCarga.
+type Destino interface {
+	// abrir prepara la escritura de una tabla para la corrida r; puede
+	// llamarse desde varias goroutines a la vez, una por tabla.
+	abrir(ctx context.Context, r *Corrida, tabla tablaSpec) (sink, error)
+	// particionable indica si admite varios sinks abiertos a la vez sobre la
+	// misma tabla, como los workers de Fact_Ventas.
+	particionable() bool
+	// confirmado indica si un bloque de la tabla (o la tabla completa, con
+	// bloque tablaCompleta) ya quedó confirmado en una corrida anterior.
+	confirmado(tabla string, bloque int) bool
+}
+
+// sink recibe las filas de una tabla en lotes de hasta filasPorLote filas, en el
+// mismo orden de tabla.columnas.
+type sink interface {
+	escribir(filas [][]interface{}) error
+	// confirmar marca un punto de commit: lo escrito hasta aquí es durable.
+	confirmar() error
+	// cerrar confirma lo pendiente y libera los recursos de la tabla.
+	cerrar() error
+	// descartar abandona lo no confirmado; no hace nada tras cerrar.
+	descartar()
+	// metodo identifica la forma de escritura en el reporte de rendimiento.
+	metodo() string
+}
+
+// errSiguienteTransaccion marca el error de confirmar cuando el commit pasó
+// y lo que falló fue abrir la transacción siguiente: lo escrito ya es durable.
+var errSiguienteTransaccion = errors.New("abriendo la transacción siguiente")
+
+// sinkConPuntos es un sink que guarda puntos de control en la misma
+// transacción que las filas, para que se confirmen juntos.
+type sinkConPuntos interface {
+	registrarPunto(p puntoControl) error
+}
+
+// registrarPunto guarda p en s si el sink admite puntos de control.
+func registrarPunto(s sink, p puntoControl) error {
+	if conPuntos, ok := s.(sinkConPuntos); ok {
+		return conPuntos.registrarPunto(p)
+	}
+	return nil
+}
+
+// ================== CARGADOR POR TABLA ==================
+// cargador desacopla la generación de la escritura: el generador llena lotes
+// de filasPorLote filas y los publica en un canal acotado que vacía una
+// goroutine escritora dedicada al sink. Mientras el escritor espera a la base,
+// el generador ya prepara los siguientes lotes; si el escritor se atrasa, el
+// canal lleno frena al generador (backpressure) y la memoria queda acotada a
+// lotesEnCola lotes más los que reciclan los buffers.
+//
+// El primer error del escritor queda guardado: desde ahí el escritor solo
+// vacía la cola y agregar, confirmar y cerrar lo devuelven al generador.
+type cargador struct {
+	ctx    context.Context
+	r      *Corrida
+	tabla  tablaSpec
+	sink   sink
+	lote   int
+	filas  [][]interface{}
+	inicio time.Time
+	avance *avanceTabla
+
+	cola   chan pedidoEscritura
+	libres chan [][]interface{}
+	listo  chan struct{}
+	total  int // filas escritas; solo lo toca el escritor
+	lotes  int // lotes escritos; solo lo toca el escritor
+	// filas escritas desde el último commit; el escritor las suma y cerrar
+	// las lee después de esperarlo
+	sinConfirmar int
+	// suma por columna entera de esas mismas filas, en el orden de enteras
+	enteras []int
+	huella  []int64
+	cerrado bool
+	// porBloques indica que el generador marca sus propios puntos de
+	// control con marcarPunto; si no, cerrar registra uno por la tabla
+	// completa. Se fija al crear el cargador, antes de escribir.
+	porBloques bool
+	// omitida indica una tabla ya confirmada al reanudar: sus filas no se
+	// escriben ni cuentan en el progreso ni en el rendimiento
+	omitida bool
+
+	mu  sync.Mutex
+	err error
+}
+
+// Lotes que el generador puede adelantar al escritor de cada tabla.
+const lotesEnCola = 4
+
+// pedidoEscritura es un lote de filas, un punto de control o, con
+// confirmar, un punto de commit. Todos viajan por la misma cola para
+// conservar el orden.
+type pedidoEscritura struct {
+	filas     [][]interface{}
+	punto     *puntoControl
+	confirmar bool
+}
+
+// LoteError es la falla de un lote al escribirse en su destino.
+type LoteError struct {
+	Tabla string
+	// Lote es el número del lote dentro de su cargador, desde 1. En
+	// Fact_Ventas cada worker numera los suyos.
+	Lote int
+	// PrimeraClave identifica la primera fila del lote por su primera
+	// columna, p. ej. "NumeroPedido=PED-00120001".
+	PrimeraClave string
+	Err          error
+}
+
+func (e *LoteError) Error() string {
+	return fmt.Sprintf("%s, lote %d (desde %s): %v", e.Tabla, e.Lote, e.PrimeraClave, e.Err)
+}
+
+func (e *LoteError) Unwrap() error { return e.Err }
+
+func (r *Corrida) nuevoCargador(ctx context.Context, dest Destino, tabla tablaSpec) (*cargador, error) {
+	s, err := dest.abrir(ctx, r, tabla)
+	if err != nil {
+		return nil, fmt.Errorf("abriendo %s: %w", tabla.nombre, err)
+	}
+	lote := r.filasPorLote(tabla, s.metodo())
+	enteras := tabla.columnasEnteras()
+	_, omitida := s.(sinkOmitido)
+	c := &cargador{
+		omitida: omitida,
+		ctx:     ctx,
+		r:       r,
+		enteras: enteras,
+		huella:  make([]int64, len(enteras)),
+		tabla:   tabla,
+		sink:    s,
+		lote:    lote,
+		filas:   make([][]interface{}, 0, lote),
+		inicio:  time.Now(),
+		avance:  &avanceTabla{nombre: tabla.nombre},
+		cola:    make(chan pedidoEscritura, lotesEnCola),
+		libres:  make(chan [][]interface{}, lotesEnCola+1),
+		listo:   make(chan struct{}),
+	}
+	if omitida {
+		log.Printf("♻️  %s ya estaba confirmada; se omite", tabla.nombre)
+	} else {
+		c.avance = r.progreso.abrirTabla(tabla.nombre, r.filasEsperadas(dest, tabla))
+	}
+	go c.escritor()
+	return c, nil
+}
+
+// escritor vacía la cola en orden hasta que se cierra.
+func (c *cargador) escritor() {
+	defer close(c.listo)
+	for p := range c.cola {
+		if c.fallo() != nil {
+			continue
+		}
+		if p.confirmar {
+			if err := c.sink.confirmar(); err != nil {
+				if errors.Is(err, errSiguienteTransaccion) {
+					c.r.estado.confirmar(c.tabla.nombre, c.sinConfirmar, false)
+					c.sinConfirmar = 0
+					clear(c.huella)
+				}
+				c.fallar(fmt.Errorf("confirmando transacción en %s: %w", c.tabla.nombre, err))
+				continue
+			}
+			c.r.estado.confirmar(c.tabla.nombre, c.sinConfirmar, false)
+			c.sinConfirmar = 0
+			clear(c.huella)
+			continue
+		}
+		if p.punto != nil {
+			p.punto.filas = c.sinConfirmar
+			p.punto.huella = c.textoHuella()
+			if err := registrarPunto(c.sink, *p.punto); err != nil {
+				c.fallar(err)
+			}
+			continue
+		}
+		c.lotes++
+		if err := c.sink.escribir(p.filas); err != nil {
+			c.fallar(&LoteError{
+				Tabla:        c.tabla.nombre,
+				Lote:         c.lotes,
+				PrimeraClave: fmt.Sprintf("%s=%v", c.tabla.columnas[0].nombre, p.filas[0][0]),
+				Err:          err,
+			})
+			continue
+		}
+		c.total += len(p.filas)
+		c.sinConfirmar += len(p.filas)
+		for _, fila := range p.filas {
+			for i, pos := range c.enteras {
+				c.huella[i] += int64(fila[pos].(int))
+			}
+		}
+		c.avance.sumar(len(p.filas))
+
+		// Los sinks no retienen las filas: el buffer vuelve al generador
+		select {
+		case c.libres <- p.filas[:0]:
+		default:
+		}
+	}
+}
+
+// textoHuella serializa la huella de las filas sin confirmar como
+// "Columna=suma,..." para Control_Checkpoint.
+func (c *cargador) textoHuella() string {
+	partes := make([]string, len(c.enteras))
+	for i, pos := range c.enteras {
+		partes[i] = fmt.Sprintf("%s=%d", c.tabla.columnas[pos].nombre, c.huella[i])
+	}
+	return strings.Join(partes, ",")
+}
+
+func (c *cargador) fallar(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// fallo devuelve el primer error del escritor, si lo hubo.
+func (c *cargador) fallo() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+const (
+	// SQL Server admite hasta 1000 filas en un constructor VALUES; el mismo
+	// tope acota la memoria por lote en los demás motores.
+	maxFilasValues = 1000
+	// Filas por TVP o por envío a la copia masiva, que no tienen límite de
+	// parámetros.
+	filasLoteMasivo = 5000
+)
+
+// filasPorLote es el lote más grande cuyo INSERT multi-fila cabe en el límite
+// de parámetros del dialecto de la corrida, acotado por config.BatchSize si
+// es > 0.
+// Las tablas angostas envían así más filas por sentencia que las anchas.
+// Los modos bulk y tvp no usan parámetros por valor y van de a filasLoteMasivo.
+func (r *Corrida) filasPorLote(tabla tablaSpec, metodo string) int {
+	n := min(r.dialecto.maxParametros()/len(tabla.columnas), maxFilasValues)
+	if metodo == modoBulk || metodo == modoTVP {
+		n = filasLoteMasivo
+	}
+	if r.config.BatchSize > 0 {
+		n = min(n, r.config.BatchSize)
+	}
+	return max(n, 1)
+}
+
+// agregar acumula una fila y publica el lote cuando se llena.
+func (c *cargador) agregar(valores ...interface{}) error {
+	c.filas = append(c.filas, valores)
+	if len(c.filas) == c.lote {
+		return c.enviarLote()
+	}
+	return nil
+}
+
+// enviarLote publica el lote actual y toma un buffer reciclado para el
+// siguiente; se bloquea si la cola está llena. Antes de publicar devuelve el
+// error del escritor o la cancelación del contexto, si los hay.
+func (c *cargador) enviarLote() error {
+	if err := c.fallo(); err != nil {
+		return err
+	}
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", c.tabla.nombre, err)
+	}
+	if len(c.filas) == 0 {
+		return nil
+	}
+	c.cola <- pedidoEscritura{filas: c.filas}
+	select {
+	case c.filas = <-c.libres:
+	default:
+		c.filas = make([][]interface{}, 0, c.lote)
+	}
+	return nil
+}
+
+// confirmar publica lo pendiente y un punto de commit detrás de él.
+func (c *cargador) confirmar() error {
+	if err := c.enviarLote(); err != nil {
+		return err
+	}
+	c.cola <- pedidoEscritura{confirmar: true}
+	return nil
+}
+
+// marcarPunto publica lo pendiente y el punto de control p, que se confirma
+// con el siguiente commit.
+func (c *cargador) marcarPunto(p puntoControl) error {
+	if err := c.enviarLote(); err != nil {
+		return err
+	}
+	c.cola <- pedidoEscritura{punto: &p}
+	return nil
+}
+
+// terminar cierra la cola y espera a que el escritor la vacíe.
+func (c *cargador) terminar() {
+	if !c.cerrado {
+		c.cerrado = true
+		close(c.cola)
+		<-c.listo
+	}
+}
+
+// cerrar publica lo pendiente, espera al escritor, confirma la tabla y
+// registra su rendimiento. Si algo falló, devuelve el error sin confirmar y
+// queda para descartar.
+func (c *cargador) cerrar() error {
+	if err := c.enviarLote(); err != nil {
+		return err
+	}
+	c.terminar()
+	if err := c.fallo(); err != nil {
+		return err
+	}
+	if !c.porBloques {
+		p := puntoControl{tabla: c.tabla.nombre, bloque: tablaCompleta, filas: c.sinConfirmar,
+			huella: c.textoHuella()}
+		if err := registrarPunto(c.sink, p); err != nil {
+			return err
+		}
+	}
+	if err := c.sink.cerrar(); err != nil {
+		return fmt.Errorf("confirmando transacción en %s: %w", c.tabla.nombre, err)
+	}
+	// Las tablas por bloques las da por completas su generador
+	c.r.estado.confirmar(c.tabla.nombre, c.sinConfirmar, !c.porBloques)
+	c.sinConfirmar = 0
+	clear(c.huella)
+	if c.omitida {
+		return nil
+	}
+	c.r.progreso.cerrarTabla(c.avance)
+	c.r.rendimiento.registrar(medicionCarga{
+		tabla:  c.tabla.nombre,
+		metodo: c.sink.metodo(),
+		filas:  c.total,
+		inicio: c.inicio,
+		fin:    time.Now(),
+	})
+	return nil
+}
+
+// descartar abandona lo no confirmado; pensado para defer.
+func (c *cargador) descartar() {
+	c.terminar()
+	c.sink.descartar()
+}
+
+// ================== ESTADO DE LAS TABLAS ==================
+// registroEstado lleva, por tabla, las filas confirmadas en la corrida y si
+// la tabla terminó. Generar lo usa para no dar por buena una carga con
+// tablas sin confirmar y para informar cuáles quedaron a medias si falla.
+type registroEstado struct {
+	mu     sync.Mutex
+	tablas map[string]*estadoTabla
+}
+
+type estadoTabla struct {
+	filas    int
+	completa bool
+}
+
+// confirmar suma filas recién confirmadas en tabla y, con completa, la da
+// por terminada.
+func (r *registroEstado) confirmar(tabla string, filas int, completa bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tablas == nil {
+		r.tablas = make(map[string]*estadoTabla)
+	}
+	e, ok := r.tablas[tabla]
+	if !ok {
+		e = &estadoTabla{}
+		r.tablas[tabla] = e
+	}
+	e.filas += filas
+	e.completa = e.completa || completa
+}
+
+// incompletas devuelve, en orden de carga, las tablas del modelo que no
+// terminaron.
+func (r *registroEstado) incompletas() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var faltan []string
+	for _, t := range tablasModelo {
+		if e := r.tablas[t.nombre]; e == nil || !e.completa {
+			faltan = append(faltan, t.nombre)
+		}
+	}
+	return faltan
+}
+
+// reportar escribe en el log el estado final de cada tabla del modelo.
+func (r *registroEstado) reportar() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	log.Println("📋 Estado de las tablas en esta corrida:")
+	for _, t := range tablasModelo {
+		e := r.tablas[t.nombre]
+		switch {
+		case e != nil && e.completa:
+			log.Printf("   ✔ %-26s completa (%d filas)", t.nombre, e.filas)
+		case e != nil && e.filas > 0:
+			log.Printf("   ◐ %-26s parcial (%d filas confirmadas)", t.nombre, e.filas)
+		default:
+			log.Printf("   ✗ %-26s sin cargar", t.nombre)
+		}
+	}
+}
+
+// ================== DESTINO SQL ==================
+// destinoSQL escribe en la base con el dialecto de la corrida, el modo de
+// carga que config.ModosCarga asigne a cada tabla y, si config.Reintentos > 0,
+// repitiendo las transacciones que fallen por errores transitorios. Con
+// puntos, cada commit registra su punto de control en Control_Checkpoint;
+// hechos son los que ya había al reanudar.
+type destinoSQL struct {
+	db     *sql.DB
+	puntos bool
+	hechos map[clavePunto]bool
+}
+
+// NuevoDestinoSQL escribe las filas en db con el dialecto de la corrida que
+// lo use.
+func NuevoDestinoSQL(db *sql.DB) Destino {
+	return destinoSQL{db: db, puntos: true}
+}
+
+// Cada sink tiene su propia transacción y, por lo tanto, su propia conexión.
+func (destinoSQL) particionable() bool { return true }
+
+func (d destinoSQL) confirmado(tabla string, bloque int) bool {
+	return d.hechos[clavePunto{tabla, bloque}]
+}
+
+func (d destinoSQL) abrir(ctx context.Context, r *Corrida, tabla tablaSpec) (sink, error) {
+	if d.confirmado(tabla.nombre, tablaCompleta) {
+		return sinkOmitido{}, nil
+	}
+	if tabla.nombre == tablaDimTiempo.nombre {
+		if err := r.asegurarNombreFeriado(ctx, d.db); err != nil {
+			return nil, err
+		}
+	}
+	s := &sinkSQL{ctx: ctx, r: r, db: d.db, tabla: tabla, columnas: tabla.nombresColumnas()}
+	if d.puntos {
+		s.hash = r.config.hashDatos()
+	}
+	var out sink = s
+	var err error
+	switch r.config.ModosCarga.modo(tabla.nombre) {
+	case modoBulk:
+		out, err = abrirCopia(s)
+	case modoTVP:
+		out, err = abrirTVP(ctx, s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.config.Reintentos > 0 {
+		conReintentos := &sinkConReintentos{sink: out, base: s}
+		if err := conReintentos.iniciar(); err != nil {
+			return nil, err
+		}
+		return conReintentos, nil
+	}
+	if err := s.iniciar(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// sinkSQL inserta cada lote con insertBatchTx dentro de una transacción que
+// se renueva en cada punto de commit.
+type sinkSQL struct {
+	ctx      context.Context
+	r        *Corrida
+	db       *sql.DB
+	tabla    tablaSpec
+	columnas []string
+	tx       *sql.Tx
+	hash     string // de config.hashDatos(); vacío = sin puntos de control
+}
+
+// iniciar, cerrar y descartar auditan la transacción: abrir una sobre otra
+// o confirmar sin tenerla es un error del generador y se informa como tal.
+func (s *sinkSQL) iniciar() error {
+	if s.tx != nil {
+		return fmt.Errorf("%s: ya hay una transacción abierta", s.tabla.nombre)
+	}
+	tx, err := s.db.BeginTx(s.ctx, nil)
+	if err != nil {
+		return err
+	}
+	s.tx = tx
+	return nil
+}
+
+func (s *sinkSQL) metodo() string { return modoInsert }
+
+func (s *sinkSQL) escribir(filas [][]interface{}) error {
+	return insertBatchTx(s.ctx, s.tx, s.r.dialecto, s.tabla.nombre, s.columnas, filas)
+}
+
+func (s *sinkSQL) registrarPunto(p puntoControl) error {
+	if s.hash == "" {
+		return nil
+	}
+	return s.r.insertarPunto(s.ctx, s.tx, s.hash, p)
+}
+
+func (s *sinkSQL) confirmar() error {
+	if err := s.cerrar(); err != nil {
+		return err
+	}
+	if err := s.iniciar(); err != nil {
+		return fmt.Errorf("%w: %w", errSiguienteTransaccion, err)
+	}
+	return nil
+}
+
+func (s *sinkSQL) cerrar() error {
+	if s.tx == nil {
+		return fmt.Errorf("%s: no hay transacción abierta para confirmar", s.tabla.nombre)
+	}
+	err := s.tx.Commit()
+	s.tx = nil
+	return err
+}
+
+func (s *sinkSQL) descartar() {
+	if s.tx == nil {
+		return
+	}
+	// ErrTxDone: el motor ya la deshizo (p. ej. al cancelarse el contexto);
+	// con la conexión perdida, el servidor la deshace al cerrarla
+	if err := s.tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) && !conexionPerdida(err) {
+		log.Printf("⚠️  %s: no se pudo deshacer la transacción: %v", s.tabla.nombre, err)
+	}
+	s.tx = nil
+}
@@ -0,0 +1,83 @@
+package generador
+
+import "testing"
+
+func TestFilasPorLote(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		dialecto  dialecto
+		batchSize int
+		tabla     tablaSpec
+		metodo    string
+		esperadas int
+	}{
+		// 2098 parámetros / 14 columnas
+		{"sqlserver ancha", sqlServer{}, 0, tablaFactVentas, modoInsert, 149},
+		// 2098 / 4 = 524
+		{"sqlserver angosta", sqlServer{}, 0, tablaDimCanalVenta, modoInsert, 524},
+		// 65535 / 14 supera el tope de VALUES
+		{"postgres acotada por VALUES", postgres{}, 0, tablaFactVentas, modoInsert, maxFilasValues},
+		{"sqlite acotada por VALUES", sqlite{}, 0, tablaDimTiempo, modoInsert, maxFilasValues},
+		{"bulk sin límite de parámetros", postgres{}, 0, tablaFactVentas, modoBulk, filasLoteMasivo},
+		{"tvp sin límite de parámetros", sqlServer{}, 0, tablaFactVentas, modoTVP, filasLoteMasivo},
+		{"batch_size acota insert", sqlServer{}, 100, tablaFactVentas, modoInsert, 100},
+		{"batch_size acota bulk", postgres{}, 2000, tablaFactVentas, modoBulk, 2000},
+		{"batch_size no supera el límite", sqlServer{}, 10_000, tablaFactVentas, modoInsert, 149},
+		{"batch_size mínimo", sqlServer{}, 1, tablaFactVentas, modoInsert, 1},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			cfg := configPredeterminada
+			cfg.BatchSize = c.batchSize
+			r := &Corrida{config: cfg, dialecto: c.dialecto}
+			if got := r.filasPorLote(c.tabla, c.metodo); got != c.esperadas {
+				t.Errorf("filasPorLote(%s, %s) = %d; se esperaba %d", c.tabla.nombre, c.metodo, got, c.esperadas)
+			}
+		})
+	}
+}
+
+// Dos corridas en el mismo proceso no se pisan la configuración.
+func TestCorridasIndependientes(t *testing.T) {
+	chica := configPredeterminada
+	chica.BatchSize = 10
+	a, err := NuevaCorrida(chica, DialectoSQLServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NuevaCorrida(configPredeterminada, DialectoPostgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.filasPorLote(tablaFactVentas, modoInsert); got != 10 {
+		t.Errorf("corrida a: %d filas por lote; se esperaba 10", got)
+	}
+	if got := b.filasPorLote(tablaFactVentas, modoInsert); got != maxFilasValues {
+		t.Errorf("corrida b: %d filas por lote; se esperaba %d", got, maxFilasValues)
+	}
+	if _, err := NuevaCorrida(chica, "oracle"); err == nil {
+		t.Error("NuevaCorrida aceptó un dialecto desconocido")
+	}
+}
+
+// Una tabla con commits parciales sigue incompleta hasta que se la da por
+// terminada.
+func TestRegistroEstadoIncompletas(t *testing.T) {
+	var e registroEstado
+	for _, tabla := range tablasModelo {
+		if tabla.nombre != tablaFactVentas.nombre && tabla.nombre != tablaFactMetricasWeb.nombre {
+			e.confirmar(tabla.nombre, 10, true)
+		}
+	}
+	e.confirmar(tablaFactVentas.nombre, 10_000, false)
+
+	faltan := e.incompletas()
+	if len(faltan) != 2 || faltan[0] != tablaFactVentas.nombre || faltan[1] != tablaFactMetricasWeb.nombre {
+		t.Fatalf("incompletas = %v; se esperaba [Fact_Ventas Fact_MetricasWeb]", faltan)
+	}
+	e.confirmar(tablaFactVentas.nombre, 0, true)
+	e.confirmar(tablaFactMetricasWeb.nombre, 24, true)
+	if faltan := e.incompletas(); len(faltan) > 0 {
+		t.Errorf("incompletas = %v; se esperaba ninguna", faltan)
+	}
+}
@@ -0,0 +1,360 @@
+package generador
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ================== TABLAS DE CONTROL ==================
+// Control_Ejecucion guarda el perfil y la configuración completa de cada
+// corrida del generador para poder reproducir cualquier dataset.
+const ddlControlEjecucion = `IF OBJECT_ID('Control_Ejecucion', 'U') IS NULL
+CREATE TABLE Control_Ejecucion (
+    IDEjecucion BIGINT IDENTITY(1,1) PRIMARY KEY,
+    Perfil NVARCHAR(100) NOT NULL,
+    ConfigJSON NVARCHAR(MAX) NOT NULL,
+    FechaInicio DATETIME2 NOT NULL,
+    FechaFin DATETIME2 NULL,
+    Estado NVARCHAR(20) NOT NULL
+)`
+
+// Estados de una corrida en Control_Ejecucion.
+const (
+	estadoEnCurso    = "EN_CURSO"
+	EstadoCompletada = "COMPLETADA"
+	EstadoFallida    = "FALLIDA"
+	// La carga se canceló (SIGINT/SIGTERM); se puede seguir con -resume
+	EstadoInterrumpida = "INTERRUMPIDA"
+)
+
+// Control_Checkpoint guarda un punto de control por cada commit de la carga:
+// uno por bloque de Fact_Ventas y uno por tabla en las demás, que se cargan
+// en una sola transacción. Cada fila se inserta en la misma transacción que
+// los datos que cubre, así que nunca hay checkpoint sin datos ni al revés, y
+// la suma de Filas por tabla es lo que el generador cargó desde la última
+// limpieza. Huella suma cada columna entera de esas filas ("Columna=suma,...")
+// para verificar el contenido y no solo la cantidad.
+const ddlControlCheckpoint = `IF OBJECT_ID('Control_Checkpoint', 'U') IS NULL
+CREATE TABLE Control_Checkpoint (
+    HashConfig NVARCHAR(64) NOT NULL,
+    Tabla NVARCHAR(50) NOT NULL,
+    Bloque INT NOT NULL,
+    UltimoNumeroPedido NVARCHAR(20) NULL,
+    EstadoRNG NVARCHAR(100) NULL,
+    Filas INT NOT NULL,
+    Huella NVARCHAR(1000) NULL,
+    FechaConfirmacion DATETIME2 NOT NULL,
+    PRIMARY KEY (HashConfig, Tabla, Bloque)
+)`
+
+// Control_Destino marca la base como destino protegido. La marca vive en la
+// base, así que la reconoce cualquier conexión que llegue a ella, con o sin
+// -target y escriba como escriba sus variables. Solo se quita a mano.
+const ddlControlDestino = `IF OBJECT_ID('Control_Destino', 'U') IS NULL
+CREATE TABLE Control_Destino (
+    Nombre NVARCHAR(100) NOT NULL PRIMARY KEY,
+    FechaMarca DATETIME2 NOT NULL
+)`
+
+func (r *Corrida) asegurarTablasControl(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, r.dialecto.traducirDDL(ddlControlEjecucion)); err != nil {
+		return fmt.Errorf("creando Control_Ejecucion: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, r.dialecto.traducirDDL(ddlControlCheckpoint)); err != nil {
+		return fmt.Errorf("creando Control_Checkpoint: %w", err)
+	}
+	// Las bases con puntos de control anteriores a la huella no la tienen
+	if err := r.asegurarColumna(ctx, db, "Control_Checkpoint", "Huella", "NVARCHAR(1000) NULL"); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, r.dialecto.traducirDDL(ddlControlDestino)); err != nil {
+		return fmt.Errorf("creando Control_Destino: %w", err)
+	}
+	return nil
+}
+
+// asegurarColumna agrega columna, con el tipo T-SQL tipo, a las bases cuya
+// tabla se creó antes de que existiera.
+func (r *Corrida) asegurarColumna(ctx context.Context, db *sql.DB, tabla, columna, tipo string) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE 1 = 0", columna, tabla)); err == nil {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD %s %s", tabla, columna, r.dialecto.traducirDDL(tipo)))
+	if err != nil {
+		return fmt.Errorf("agregando %s a %s: %w", columna, tabla, err)
+	}
+	log.Printf("🔧 Columna %s agregada a %s", columna, tabla)
+	return nil
+}
+
+// RegistrarInicioEjecucion deja constancia del perfil y la configuración de
+// la corrida y devuelve su ID.
+func (r *Corrida) RegistrarInicioEjecucion(ctx context.Context, db *sql.DB) (int64, error) {
+	if _, err := r.destinoProtegido(ctx, db); err != nil {
+		return 0, err
+	}
+
+	configJSON, err := json.Marshal(r.config)
+	if err != nil {
+		return 0, fmt.Errorf("serializando configuración: %w", err)
+	}
+
+	var id int64
+	consulta := r.dialecto.insertarDevolviendoID("Control_Ejecucion", "IDEjecucion",
+		"Perfil", "ConfigJSON", "FechaInicio", "Estado")
+	err = db.QueryRowContext(ctx, consulta, r.config.Perfil, string(configJSON), time.Now(), estadoEnCurso).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("registrando ejecución: %w", err)
+	}
+
+	log.Printf("📝 Ejecución #%d registrada (perfil: %s)", id, r.config.Perfil)
+	return id, nil
+}
+
+// RegistrarFinEjecucion cierra la corrida id con el estado indicado.
+func (r *Corrida) RegistrarFinEjecucion(ctx context.Context, db *sql.DB, id int64, estado string) error {
+	d := r.dialecto
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`UPDATE Control_Ejecucion SET FechaFin = %s, Estado = %s
+		WHERE IDEjecucion = %s`, d.marcador(1), d.marcador(2), d.marcador(3)), time.Now(), estado, id)
+	if err != nil {
+		return fmt.Errorf("cerrando ejecución #%d: %w", id, err)
+	}
+	return nil
+}
+
+// EjecucionPendiente devuelve la última corrida registrada, con la
+// configuración con que se lanzó, si no llegó a completarse.
+func (r *Corrida) EjecucionPendiente(ctx context.Context, db *sql.DB) (int64, Config, error) {
+	if err := r.asegurarTablasControl(ctx, db); err != nil {
+		return 0, Config{}, err
+	}
+
+	var id int64
+	var configJSON, estado string
+	err := db.QueryRowContext(ctx, `SELECT IDEjecucion, ConfigJSON, Estado FROM Control_Ejecucion
+		WHERE IDEjecucion = (SELECT MAX(IDEjecucion) FROM Control_Ejecucion)`).Scan(&id, &configJSON, &estado)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, Config{}, errors.New("no hay ejecuciones registradas para reanudar")
+	}
+	if err != nil {
+		return 0, Config{}, fmt.Errorf("leyendo Control_Ejecucion: %w", err)
+	}
+	if estado == EstadoCompletada {
+		return 0, Config{}, fmt.Errorf("la última ejecución (#%d) ya está completa; no hay nada que reanudar", id)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal([]byte(configJSON), &cfg); err != nil {
+		return 0, Config{}, fmt.Errorf("configuración de la ejecución #%d: %w", id, err)
+	}
+	return id, cfg, nil
+}
+
+// ================== PUNTOS DE CONTROL ==================
+// Bloque de los puntos de control que cubren una tabla completa.
+const tablaCompleta = -1
+
+// puntoControl es un commit de la carga: un bloque de Fact_Ventas o una
+// tabla completa.
+type puntoControl struct {
+	tabla       string
+	bloque      int
+	ultimaClave string // NumeroPedido de la última venta del bloque
+	estadoRNG   string // estado del PCG del bloque al terminarlo, en hex
+	filas       int    // filas confirmadas con el punto; las fija el cargador
+	huella      string // suma de las columnas enteras de esas filas; ídem
+}
+
+type clavePunto struct {
+	tabla  string
+	bloque int
+}
+
+// hashDatos identifica los datos que produce cfg: semilla, volúmenes y
+// fechas. Los campos operativos (workers, conexiones, lotes, modos de carga,
+// reintentos) y el nombre del perfil no cambian las filas y no cuentan.
+func (cfg Config) hashDatos() string {
+	cfg.Perfil = ""
+	cfg.BatchSize = 0
+	cfg.VentasWorkers = 0
+	cfg.MaxConexiones = 0
+	cfg.Reintentos = 0
+	cfg.ModosCarga = nil
+	contenido, _ := json.Marshal(cfg)
+	suma := sha256.Sum256(contenido)
+	return hex.EncodeToString(suma[:])
+}
+
+// insertarPunto registra p dentro de tx, antes de su commit.
+func (r *Corrida) insertarPunto(ctx context.Context, tx *sql.Tx, hash string, p puntoControl) error {
+	consulta := fmt.Sprintf(`INSERT INTO Control_Checkpoint (HashConfig, Tabla, Bloque,
+		UltimoNumeroPedido, EstadoRNG, Filas, Huella, FechaConfirmacion) VALUES (%s)`, marcadores(r.dialecto, 1, 8))
+	_, err := tx.ExecContext(ctx, consulta, hash, p.tabla, p.bloque,
+		sql.NullString{String: p.ultimaClave, Valid: p.ultimaClave != ""},
+		sql.NullString{String: p.estadoRNG, Valid: p.estadoRNG != ""},
+		p.filas, p.huella, time.Now())
+	if err != nil {
+		return fmt.Errorf("registrando checkpoint de %s: %w", p.tabla, err)
+	}
+	return nil
+}
+
+// leerPuntos devuelve los commits ya registrados para la configuración hash.
+func (r *Corrida) leerPuntos(ctx context.Context, db *sql.DB, hash string) (map[clavePunto]bool, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT Tabla, Bloque FROM Control_Checkpoint WHERE HashConfig = %s", r.dialecto.marcador(1)), hash)
+	if err != nil {
+		return nil, fmt.Errorf("leyendo Control_Checkpoint: %w", err)
+	}
+	defer rows.Close()
+
+	puntos := map[clavePunto]bool{}
+	for rows.Next() {
+		var k clavePunto
+		if err := rows.Scan(&k.tabla, &k.bloque); err != nil {
+			return nil, err
+		}
+		puntos[k] = true
+	}
+	return puntos, rows.Err()
+}
+
+// tablasConFilasAjenas compara cada tabla del modelo con lo que registran
+// sus puntos de control (cantidad de filas y suma de cada columna entera) y
+// describe las que no coinciden: filas de más, filas cambiadas o tablas
+// cargadas antes de que existieran los puntos de control. Se suman los
+// puntos de todas las configuraciones, porque LimpiarTablas los borra junto
+// con los datos; uno que sobreviva a sus filas tampoco coincide.
+func (r *Corrida) tablasConFilasAjenas(ctx context.Context, db *sql.DB) ([]string, error) {
+	if err := r.asegurarTablasControl(ctx, db); err != nil {
+		return nil, err
+	}
+	var ajenas []string
+	for _, t := range tablasModelo {
+		enteras := t.columnasEnteras()
+		consulta := "SELECT COUNT(*)"
+		for _, pos := range enteras {
+			consulta += fmt.Sprintf(", COALESCE(SUM(CAST(%s AS BIGINT)), 0)", t.columnas[pos].nombre)
+		}
+		actual := make([]int64, len(enteras)+1)
+		destinos := make([]interface{}, len(actual))
+		for i := range actual {
+			destinos[i] = &actual[i]
+		}
+		if err := db.QueryRowContext(ctx, consulta+" FROM "+t.nombre).Scan(destinos...); err != nil {
+			return nil, fmt.Errorf("leyendo %s: %w", t.nombre, err)
+		}
+
+		cargadas, huella, err := r.huellaCargada(ctx, db, t)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case actual[0] != cargadas:
+			ajenas = append(ajenas, fmt.Sprintf("%s: %d filas, %d cargadas por el generador", t.nombre, actual[0], cargadas))
+		case huella == nil:
+			ajenas = append(ajenas, fmt.Sprintf("%s: puntos de control sin huella, no se puede verificar", t.nombre))
+		default:
+			var distintas []string
+			for i, pos := range enteras {
+				if actual[i+1] != huella[t.columnas[pos].nombre] {
+					distintas = append(distintas, t.columnas[pos].nombre)
+				}
+			}
+			if len(distintas) > 0 {
+				ajenas = append(ajenas, fmt.Sprintf("%s: %d filas, pero la suma de %s no coincide con lo cargado",
+					t.nombre, actual[0], strings.Join(distintas, ", ")))
+			}
+		}
+	}
+	return ajenas, nil
+}
+
+// huellaCargada suma las filas y las huellas de los puntos de control de la
+// tabla t. La huella es nil si algún punto no la tiene.
+func (r *Corrida) huellaCargada(ctx context.Context, db *sql.DB, t tablaSpec) (int64, map[string]int64, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT Filas, Huella FROM Control_Checkpoint WHERE Tabla = %s", r.dialecto.marcador(1)), t.nombre)
+	if err != nil {
+		return 0, nil, fmt.Errorf("leyendo Control_Checkpoint: %w", err)
+	}
+	defer rows.Close()
+
+	var filas int64
+	huella := map[string]int64{}
+	completa := true
+	for rows.Next() {
+		var n int64
+		var texto sql.NullString
+		if err := rows.Scan(&n, &texto); err != nil {
+			return 0, nil, err
+		}
+		filas += n
+		if !texto.Valid {
+			completa = false
+			continue
+		}
+		for _, parte := range strings.Split(texto.String, ",") {
+			if parte == "" {
+				continue
+			}
+			columna, valor, _ := strings.Cut(parte, "=")
+			suma, err := strconv.ParseInt(valor, 10, 64)
+			if err != nil {
+				return 0, nil, fmt.Errorf("huella inválida en Control_Checkpoint (%s): %q", t.nombre, texto.String)
+			}
+			huella[columna] += suma
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	if !completa {
+		return filas, nil, nil
+	}
+	return filas, huella, nil
+}
+
+// ReanudarDestinoSQL escribe en db como NuevoDestinoSQL, pero omite las
+// tablas y los bloques de Fact_Ventas que ya tienen punto de control para la
+// configuración de la corrida. Los generadores de las tablas omitidas corren igual, sin escribir,
+// para que las claves y los flujos aleatorios queden como en una corrida
+// sin interrupciones.
+func (r *Corrida) ReanudarDestinoSQL(ctx context.Context, db *sql.DB) (Destino, error) {
+	if err := r.asegurarTablasControl(ctx, db); err != nil {
+		return nil, err
+	}
+	hechos, err := r.leerPuntos(ctx, db, r.config.hashDatos())
+	if err != nil {
+		return nil, err
+	}
+
+	tablas, bloques := 0, 0
+	for k := range hechos {
+		if k.bloque == tablaCompleta {
+			tablas++
+		} else {
+			bloques++
+		}
+	}
+	log.Printf("♻️  Ya confirmados: %d tablas completas y %d bloques de Fact_Ventas", tablas, bloques)
+	return destinoSQL{db: db, puntos: true, hechos: hechos}, nil
+}
+
+// sinkOmitido descarta las filas de una tabla que ya estaba confirmada.
+type sinkOmitido struct{}
+
+func (sinkOmitido) escribir([][]interface{}) error { return nil }
+func (sinkOmitido) confirmar() error               { return nil }
+func (sinkOmitido) cerrar() error                  { return nil }
+func (sinkOmitido) descartar()                     {}
+func (sinkOmitido) metodo() string                 { return "omitida" }
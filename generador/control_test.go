@@ -0,0 +1,25 @@
+package generador
+
+import "testing"
+
+// Solo la semilla, los volúmenes y las fechas identifican los datos: al
+// reanudar se pueden cambiar los parámetros operativos.
+func TestHashDatosIgnoraParametrosOperativos(t *testing.T) {
+	base := configPredeterminada
+	operativa := base
+	operativa.Perfil = "1M+flags"
+	operativa.BatchSize = 100
+	operativa.VentasWorkers = 16
+	operativa.MaxConexiones = 2
+	operativa.Reintentos = 0
+	operativa.ModosCarga = ModosCarga{"*": modoBulk}
+	if base.hashDatos() != operativa.hashDatos() {
+		t.Error("los parámetros operativos cambiaron el hash de los datos")
+	}
+
+	otraSemilla := base
+	otraSemilla.Seed++
+	if base.hashDatos() == otraSemilla.hashDatos() {
+		t.Error("otra semilla dio el mismo hash de los datos")
+	}
+}
@@ -0,0 +1,367 @@
+package generador
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// ================== MODOS DE CARGA ==================
+// insert arma INSERT multi-fila de filasPorLote filas (limitado por los
+// parámetros por sentencia); bulk usa el protocolo de copia masiva del motor
+// (INSERT BULK en SQL Server, COPY en PostgreSQL) sin ese límite; tvp envía
+// cada lote como table-valued parameter (solo SQL Server).
+const (
+	modoInsert = "insert"
+	modoBulk   = "bulk"
+	modoTVP    = "tvp"
+)
+
+var modosValidos = []string{modoInsert, modoBulk, modoTVP}
+
+// ModosValidos devuelve los métodos de carga admitidos.
+func ModosValidos() []string { return slices.Clone(modosValidos) }
+
+// ModosCarga asigna un modo a cada tabla; la clave "*" es el modo por defecto.
+// Como flag se escribe "Fact_Ventas=bulk,Fact_Finanzas=tvp" o "bulk" para
+// todas las tablas.
+type ModosCarga map[string]string
+
+// modo devuelve el método de carga de una tabla.
+func (m ModosCarga) modo(tabla string) string {
+	if v, ok := m[tabla]; ok {
+		return v
+	}
+	if v, ok := m["*"]; ok {
+		return v
+	}
+	return modoInsert
+}
+
+func (m ModosCarga) String() string {
+	pares := make([]string, 0, len(m))
+	for tabla, modo := range m {
+		pares = append(pares, tabla+"="+modo)
+	}
+	sort.Strings(pares)
+	return strings.Join(pares, ",")
+}
+
+// Set implementa flag.Value; agrega las asignaciones a las ya existentes.
+func (m *ModosCarga) Set(s string) error {
+	if *m == nil {
+		*m = ModosCarga{}
+	}
+	for _, par := range strings.Split(s, ",") {
+		par = strings.TrimSpace(par)
+		if par == "" {
+			continue
+		}
+		tabla, modo, ok := strings.Cut(par, "=")
+		if !ok {
+			tabla, modo = "*", par
+		}
+		(*m)[strings.TrimSpace(tabla)] = strings.ToLower(strings.TrimSpace(modo))
+	}
+	return nil
+}
+
+// validar revisa los nombres de tabla y de modo.
+func (m ModosCarga) validar() error {
+	var errs []error
+	for tabla, modo := range m {
+		if tabla != "*" && !tablaDelModelo(tabla) {
+			errs = append(errs, fmt.Errorf("modos_carga: tabla desconocida %q", tabla))
+		}
+		if !slices.Contains(modosValidos, modo) {
+			errs = append(errs, fmt.Errorf("modos_carga: modo %q inválido para %s (use %s)",
+				modo, tabla, strings.Join(modosValidos, ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ModosSoportados verifica que el dialecto de la corrida admita los modos de
+// carga de su configuración.
+func (r *Corrida) ModosSoportados() error {
+	for _, modo := range r.config.ModosCarga {
+		if err := modoSoportado(modo, r.dialecto); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func modoSoportado(modo string, d dialecto) error {
+	switch modo {
+	case modoBulk:
+		_, err := d.copiaMasiva(tablaFactVentas)
+		return err
+	case modoTVP:
+		if d.nombre() != DialectoSQLServer {
+			return fmt.Errorf("la carga %s solo está disponible en %s", modoTVP, DialectoSQLServer)
+		}
+	}
+	return nil
+}
+
+func tablaDelModelo(nombre string) bool {
+	for _, t := range tablasModelo {
+		if t.nombre == nombre {
+			return true
+		}
+	}
+	return false
+}
+
+// ================== SINK DE COPIA MASIVA ==================
+// sinkCopia mantiene abierta una sentencia de copia masiva por transacción:
+// cada fila se envía con Exec(fila...) y el driver la acumula; en cada punto
+// de commit Exec() sin argumentos vacía el buffer y se confirma.
+type sinkCopia struct {
+	*sinkSQL
+	sentencia string
+	stmt      *sql.Stmt
+}
+
+func (s *sinkCopia) metodo() string { return modoBulk }
+
+func (s *sinkCopia) escribir(filas [][]interface{}) error {
+	if s.stmt == nil {
+		stmt, err := s.tx.PrepareContext(s.ctx, s.sentencia)
+		if err != nil {
+			return err
+		}
+		s.stmt = stmt
+	}
+	for _, fila := range filas {
+		if _, err := s.stmt.ExecContext(s.ctx, fila...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// vaciar envía las filas pendientes y cierra la sentencia.
+func (s *sinkCopia) vaciar() error {
+	if s.stmt == nil {
+		return nil
+	}
+	stmt := s.stmt
+	s.stmt = nil
+	if _, err := stmt.ExecContext(s.ctx); err != nil {
+		stmt.Close()
+		return err
+	}
+	return stmt.Close()
+}
+
+// registrarPunto cierra antes la copia en curso: mientras está abierta la
+// transacción no admite otras sentencias.
+func (s *sinkCopia) registrarPunto(p puntoControl) error {
+	if err := s.vaciar(); err != nil {
+		return err
+	}
+	return s.sinkSQL.registrarPunto(p)
+}
+
+func (s *sinkCopia) confirmar() error {
+	if err := s.vaciar(); err != nil {
+		return err
+	}
+	return s.sinkSQL.confirmar()
+}
+
+func (s *sinkCopia) cerrar() error {
+	if err := s.vaciar(); err != nil {
+		return err
+	}
+	return s.sinkSQL.cerrar()
+}
+
+func (s *sinkCopia) descartar() {
+	if s.stmt != nil {
+		s.stmt.Close()
+		s.stmt = nil
+	}
+	s.sinkSQL.descartar()
+}
+
+// ================== RENDIMIENTO POR TABLA ==================
+// medicionCarga es la escritura de una tabla, desde que se abre hasta que se
+// confirma, con el método usado.
+type medicionCarga struct {
+	tabla       string
+	metodo      string
+	filas       int
+	inicio, fin time.Time
+}
+
+func (m medicionCarga) duracion() time.Duration { return m.fin.Sub(m.inicio) }
+
+func (m medicionCarga) filasPorSegundo() float64 {
+	if m.duracion() <= 0 {
+		return 0
+	}
+	return float64(m.filas) / m.duracion().Seconds()
+}
+
+// registroRendimiento junta las mediciones de las tablas, que pueden
+// cargarse desde varias goroutines.
+type registroRendimiento struct {
+	mu         sync.Mutex
+	mediciones []medicionCarga
+	reintentos map[claveRendimiento]int
+}
+
+type claveRendimiento struct{ tabla, metodo string }
+
+// registrar acumula la medición; los sinks paralelos de una misma tabla y
+// método se suman en una sola, con el tiempo de pared de todos ellos.
+func (r *registroRendimiento) registrar(m medicionCarga) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, previa := range r.mediciones {
+		if previa.tabla == m.tabla && previa.metodo == m.metodo {
+			previa.filas += m.filas
+			if m.inicio.Before(previa.inicio) {
+				previa.inicio = m.inicio
+			}
+			if m.fin.After(previa.fin) {
+				previa.fin = m.fin
+			}
+			r.mediciones[i] = previa
+			return
+		}
+	}
+	r.mediciones = append(r.mediciones, m)
+}
+
+// sumarReintento cuenta una transacción repetida por un error transitorio.
+func (r *registroRendimiento) sumarReintento(tabla, metodo string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reintentos == nil {
+		r.reintentos = map[claveRendimiento]int{}
+	}
+	r.reintentos[claveRendimiento{tabla, metodo}]++
+}
+
+// totalReintentos suma los reintentos de todas las tablas.
+func (r *registroRendimiento) totalReintentos() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	total := 0
+	for _, n := range r.reintentos {
+		total += n
+	}
+	return total
+}
+
+// imprimir escribe el rendimiento en el orden de carga del modelo.
+func (r *registroRendimiento) imprimir(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	orden := map[string]int{}
+	for i, t := range tablasModelo {
+		orden[t.nombre] = i
+	}
+	sort.SliceStable(r.mediciones, func(i, j int) bool {
+		return orden[r.mediciones[i].tabla] < orden[r.mediciones[j].tabla]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Tabla\tMétodo\tFilas\tTiempo\tFilas/s\tReintentos\t")
+	for _, m := range r.mediciones {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%.0f\t%d\t\n",
+			m.tabla, m.metodo, m.filas, m.duracion().Round(time.Millisecond), m.filasPorSegundo(),
+			r.reintentos[claveRendimiento{m.tabla, m.metodo}])
+	}
+	tw.Flush()
+}
+
+// registrarRendimientoEnLog agrega la tabla de rendimiento al log de la corrida.
+func (r *Corrida) registrarRendimientoEnLog() {
+	var b strings.Builder
+	r.rendimiento.imprimir(&b)
+	log.Printf("\n⏱️ Rendimiento de carga por tabla:\n%s", b.String())
+	if n := r.rendimiento.totalReintentos(); n > 0 {
+		log.Printf("🔁 %d transacciones repetidas por errores transitorios", n)
+	}
+}
+
+// abrirCopia crea el sink bulk de una tabla en el dialecto de la corrida.
+func abrirCopia(base *sinkSQL) (sink, error) {
+	sentencia, err := base.r.dialecto.copiaMasiva(base.tabla)
+	if err != nil {
+		return nil, err
+	}
+	return &sinkCopia{sinkSQL: base, sentencia: sentencia}, nil
+}
+
+// ================== BENCH ==================
+// CompararMetodos carga las dimensiones una vez y luego Fact_Ventas con cada
+// método, vaciándola entre corridas, y escribe la comparación en w. La
+// semilla es la misma, así que todos los métodos cargan exactamente las
+// mismas filas. Los métodos que el dialecto de la corrida no admite se
+// omiten. Espera las tablas del modelo vacías.
+func (r *Corrida) CompararMetodos(ctx context.Context, db *sql.DB, metodos []string, w io.Writer) error {
+	var comparar []string
+	for _, m := range metodos {
+		if !slices.Contains(modosValidos, m) {
+			return fmt.Errorf("método %q inválido (use %s)", m, strings.Join(modosValidos, ", "))
+		}
+		if err := modoSoportado(m, r.dialecto); err != nil {
+			log.Printf("⚠️  Se omite %s: %v", m, err)
+			continue
+		}
+		comparar = append(comparar, m)
+	}
+
+	if err := r.asegurarTablasControl(ctx, db); err != nil {
+		return err
+	}
+	// Los puntos de control cuentan las filas del generador (ver
+	// AutorizarLimpieza); los de Fact_Ventas se borran con cada recarga
+	dest := destinoSQL{db: db, puntos: true}
+	dims, err := r.poblarDimensiones(ctx, dest)
+	if err != nil {
+		return err
+	}
+
+	// Cada método carga con su propia corrida; solo se reportan las de
+	// Fact_Ventas, que comparten el registro de rendimiento
+	rendimiento := &registroRendimiento{}
+	for _, m := range comparar {
+		log.Printf("\n⏱️ Fact_Ventas con %s...", m)
+		if _, err := db.ExecContext(ctx, "DELETE FROM "+tablaFactVentas.nombre); err != nil {
+			return fmt.Errorf("vaciando %s: %w", tablaFactVentas.nombre, err)
+		}
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("DELETE FROM Control_Checkpoint WHERE Tabla = %s",
+			r.dialecto.marcador(1)), tablaFactVentas.nombre); err != nil {
+			return fmt.Errorf("vaciando Control_Checkpoint: %w", err)
+		}
+		cfg := r.config
+		cfg.ModosCarga = ModosCarga{tablaFactVentas.nombre: m}
+		carga := r.ConConfig(cfg)
+		carga.rendimiento = rendimiento
+		if err := carga.populateFactVentas(ctx, dest, dims.productoIDs, dims.clienteIDs, dims.sucursalIDs,
+			dims.empleadoIDs, dims.canalIDs, dims.estadoIDs, dims.tiempo); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w)
+	rendimiento.imprimir(w)
+	return nil
+}
@@ -0,0 +1,81 @@
+package generador
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestModosCargaSet(t *testing.T) {
+	casos := []struct {
+		valores   []string
+		esperados ModosCarga
+	}{
+		{[]string{"bulk"}, ModosCarga{"*": "bulk"}},
+		{[]string{" Fact_Ventas = BULK , Fact_Finanzas=tvp,"}, ModosCarga{"Fact_Ventas": "bulk", "Fact_Finanzas": "tvp"}},
+		// Cada -load-mode se suma a los anteriores y el último gana
+		{[]string{"insert", "Fact_Ventas=bulk", "Fact_Ventas=tvp"}, ModosCarga{"*": "insert", "Fact_Ventas": "tvp"}},
+		{[]string{""}, ModosCarga{}},
+	}
+	for _, c := range casos {
+		var m ModosCarga
+		for _, v := range c.valores {
+			if err := m.Set(v); err != nil {
+				t.Fatalf("Set(%q): %v", v, err)
+			}
+		}
+		if !maps.Equal(m, c.esperados) {
+			t.Errorf("Set(%q) = %v; se esperaba %v", c.valores, m, c.esperados)
+		}
+	}
+}
+
+func TestModosCargaModo(t *testing.T) {
+	m := ModosCarga{"*": modoBulk, tablaFactVentas.nombre: modoTVP}
+	if got := m.modo(tablaFactVentas.nombre); got != modoTVP {
+		t.Errorf("modo de Fact_Ventas = %s; se esperaba %s", got, modoTVP)
+	}
+	if got := m.modo(tablaDimCliente.nombre); got != modoBulk {
+		t.Errorf("modo por defecto = %s; se esperaba %s", got, modoBulk)
+	}
+	if got := (ModosCarga{}).modo(tablaDimCliente.nombre); got != modoInsert {
+		t.Errorf("sin modos = %s; se esperaba %s", got, modoInsert)
+	}
+}
+
+func TestModosCargaValidar(t *testing.T) {
+	validos := ModosCarga{"*": modoInsert, "Fact_Ventas": modoBulk, "Fact_Finanzas": modoTVP}
+	if err := validos.validar(); err != nil {
+		t.Errorf("validar(%v): %v", validos, err)
+	}
+
+	err := ModosCarga{"Fact_Compras": modoBulk, "Fact_Ventas": "copy"}.validar()
+	if err == nil {
+		t.Fatal("validar aceptó una tabla y un modo desconocidos")
+	}
+	for _, parte := range []string{`tabla desconocida "Fact_Compras"`, `modo "copy" inválido para Fact_Ventas`} {
+		if !strings.Contains(err.Error(), parte) {
+			t.Errorf("el error no menciona %s: %v", parte, err)
+		}
+	}
+}
+
+func TestModoSoportado(t *testing.T) {
+	casos := []struct {
+		modo     string
+		dialecto dialecto
+		admitido bool
+	}{
+		{modoInsert, sqlite{}, true},
+		{modoBulk, sqlServer{}, true},
+		{modoBulk, postgres{}, true},
+		{modoBulk, sqlite{}, false},
+		{modoTVP, sqlServer{}, true},
+		{modoTVP, postgres{}, false},
+	}
+	for _, c := range casos {
+		if err := modoSoportado(c.modo, c.dialecto); (err == nil) != c.admitido {
+			t.Errorf("modoSoportado(%s, %s) = %v; admitido: %t", c.modo, c.dialecto.nombre(), err, c.admitido)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package generador
+
+import (
+	"sync"
+)
+
+// ================== CORRIDA ==================
+// Corrida es el estado de una carga: la configuración, el dialecto, el
+// monitor de progreso y los registros de estado y rendimiento por tabla. Se
+// arma con NuevaCorrida y se pasa a todas las operaciones del paquete, así
+// que dos corridas en el mismo proceso no comparten nada.
+type Corrida struct {
+	config      Config
+	dialecto    dialecto
+	progreso    *monitorProgreso
+	estado      *registroEstado
+	rendimiento *registroRendimiento
+
+	// Tipos de tabla TVP ya verificados. Los workers de Fact_Ventas abren
+	// sus sinks a la vez: el mutex evita que dos creen el mismo tipo.
+	muTiposTVP sync.Mutex
+	tiposTVP   map[string]bool
+}
+
+// NuevaCorrida prepara una carga con cfg sobre el dialecto motor
+// (sqlserver, postgres o sqlite), con el progreso apagado. cfg debe venir
+// validada y con las fechas resueltas para generar; las operaciones que no
+// generan solo usan MaxConexiones.
+func NuevaCorrida(cfg Config, motor string) (*Corrida, error) {
+	d, err := buscarDialecto(motor)
+	if err != nil {
+		return nil, err
+	}
+	return &Corrida{
+		config:      cfg,
+		dialecto:    d,
+		progreso:    &monitorProgreso{modo: ProgresoOff},
+		estado:      &registroEstado{},
+		rendimiento: &registroRendimiento{},
+	}, nil
+}
+
+// ConConfig devuelve una corrida nueva con cfg, sobre el mismo dialecto y con
+// el mismo modo de progreso; p. ej. la de una ejecución que se reanuda.
+func (r *Corrida) ConConfig(cfg Config) *Corrida {
+	return &Corrida{
+		config:      cfg,
+		dialecto:    r.dialecto,
+		progreso:    &monitorProgreso{modo: r.progreso.modo},
+		estado:      &registroEstado{},
+		rendimiento: &registroRendimiento{},
+	}
+}
+
+// Config devuelve la configuración de la corrida.
+func (r *Corrida) Config() Config { return r.config }
+
+// Dialecto devuelve el nombre del motor de la corrida.
+func (r *Corrida) Dialecto() string { return r.dialecto.nombre() }
+
+// TraducirDDL adapta un script DDL escrito en T-SQL al dialecto de la corrida.
+func (r *Corrida) TraducirDDL(script string) string { return r.dialecto.traducirDDL(script) }
@@ -0,0 +1,436 @@
+package generador
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// ================== DIALECTOS SQL ==================
+// dialecto encapsula lo que cambia entre motores: driver y conexión,
+// marcadores de parámetros, límites y la traducción del DDL T-SQL de
+// 01_Esquema_Estrella.sql. Los generadores no dependen del motor.
+type dialecto interface {
+	nombre() string
+	// abrir crea el *sql.DB con el driver y las variables de entorno del motor.
+	abrir() (*sql.DB, error)
+	// identidad normaliza la base a la que apuntan las variables que devuelve
+	// valor, para reconocerla aunque se escriban de otra forma.
+	identidad(valor func(string) string) string
+	// marcador devuelve el n-ésimo parámetro posicional (base 1).
+	marcador(n int) string
+	// maxParametros es el máximo de parámetros por sentencia.
+	maxParametros() int
+	// traducirDDL adapta un script DDL escrito en T-SQL.
+	traducirDDL(script string) string
+	// ejecutarLote corre un lote de un script ya traducido.
+	ejecutarLote(ctx context.Context, db *sql.DB, lote string) error
+	// insertarDevolviendoID arma un INSERT que devuelve la columna IDENTITY.
+	insertarDevolviendoID(tabla, id string, columnas ...string) string
+	// copiaMasiva devuelve la sentencia que, preparada en una transacción,
+	// recibe las filas de a una por Exec y las envía con Exec() sin argumentos.
+	copiaMasiva(tabla tablaSpec) (string, error)
+	// transitorio indica si el motor rechazó la operación por una condición
+	// pasajera (throttling, failover, deadlock) sin confirmar nada, de modo
+	// que deshacer y repetir la transacción completa no duplica filas.
+	transitorio(err error) bool
+	// clavesForaneas lee del catálogo las claves entre tablas del modelo.
+	clavesForaneas(ctx context.Context, db *sql.DB) ([]claveForanea, error)
+	// truncar vacía las tablas, ya ordenadas hijas primero, con TRUNCATE;
+	// errSinTruncate si el motor no lo tiene.
+	truncar(ctx context.Context, db *sql.DB, orden []string, claves []claveForanea) error
+}
+
+const (
+	DialectoSQLServer = "sqlserver"
+	DialectoPostgres  = "postgres"
+	DialectoSQLite    = "sqlite"
+)
+
+var dialectos = map[string]dialecto{
+	DialectoSQLServer: sqlServer{},
+	DialectoPostgres:  postgres{},
+	DialectoSQLite:    sqlite{},
+}
+
+// buscarDialecto devuelve el motor por nombre: sqlserver, postgres o sqlite.
+func buscarDialecto(nombre string) (dialecto, error) {
+	d, ok := dialectos[strings.ToLower(nombre)]
+	if !ok {
+		return nil, fmt.Errorf("dialecto desconocido %q (use %s, %s o %s)", nombre,
+			DialectoSQLServer, DialectoPostgres, DialectoSQLite)
+	}
+	return d, nil
+}
+
+// marcadores devuelve "p1,p2,...,pn" empezando en el parámetro desde.
+func marcadores(d dialecto, desde, n int) string {
+	m := make([]string, n)
+	for i := range m {
+		m[i] = d.marcador(desde + i)
+	}
+	return strings.Join(m, ",")
+}
+
+// hostNormalizado deja solo el nombre del servidor: en minúsculas, sin el
+// prefijo tcp:, el puerto (",1433" o ":1433") ni el punto final.
+func hostNormalizado(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimPrefix(host, "tcp:")
+	host, _, _ = strings.Cut(host, ",")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// conexionPerdida indica que se cortó la conexión con el servidor (failover,
+// reinicio, red): el driver devuelve driver.ErrBadConn, EOF o un error de red
+// en vez de un código del motor. Si el corte llega durante el commit, este
+// pudo aplicarse; al repetirlo, la clave primaria de Control_Checkpoint
+// rechaza el punto de control ya registrado y la carga falla sin duplicar
+// filas. La cancelación del contexto no cuenta.
+func conexionPerdida(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var errRed net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.As(err, &errRed)
+}
+
+// ================== SQL SERVER / AZURE SQL ==================
+type sqlServer struct{}
+
+func (sqlServer) nombre() string { return DialectoSQLServer }
+
+func (sqlServer) abrir() (*sql.DB, error) {
+	var faltan []string
+	env := func(k string) string {
+		v := os.Getenv(k)
+		if v == "" {
+			faltan = append(faltan, k)
+		}
+		return v
+	}
+	server := env("AZURE_SQL_SERVER")
+	port := env("AZURE_SQL_PORT")
+	user := env("AZURE_SQL_USER")
+	password := env("AZURE_SQL_PASSWORD")
+	database := env("AZURE_SQL_DATABASE")
+	if len(faltan) > 0 {
+		return nil, fmt.Errorf("faltan variables de entorno: %s", strings.Join(faltan, ", "))
+	}
+
+	connString := fmt.Sprintf("server=%s;port=%s;user id=%s;password=%s;database=%s;encrypt=true",
+		server, port, user, password, database)
+	return sql.Open("sqlserver", connString)
+}
+
+// Los nombres de base no distinguen mayúsculas con la intercalación por
+// defecto de Azure SQL.
+func (sqlServer) identidad(valor func(string) string) string {
+	return hostNormalizado(valor("AZURE_SQL_SERVER")) + "/" +
+		strings.ToLower(strings.TrimSpace(valor("AZURE_SQL_DATABASE")))
+}
+
+func (sqlServer) marcador(n int) string { return fmt.Sprintf("@p%d", n) }
+
+// Límite de 2100 parámetros por RPC de SQL Server, menos @stmt y @params de
+// sp_executesql
+func (sqlServer) maxParametros() int { return 2098 }
+
+func (sqlServer) traducirDDL(script string) string { return script }
+
+func (sqlServer) ejecutarLote(ctx context.Context, db *sql.DB, lote string) error {
+	return ejecutarLoteTSQL(ctx, db, lote)
+}
+
+func (sqlServer) insertarDevolviendoID(tabla, id string, columnas ...string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) OUTPUT INSERTED.%s VALUES (%s)",
+		tabla, strings.Join(columnas, ", "), id, marcadores(sqlServer{}, 1, len(columnas)))
+}
+
+// copiaMasiva usa el bulk copy de TDS (INSERT BULK). CheckConstraints evita
+// que las FK y los CHECK queden marcados como no confiables tras la carga.
+func (sqlServer) copiaMasiva(tabla tablaSpec) (string, error) {
+	return mssql.CopyIn(tabla.nombre, mssql.BulkOptions{CheckConstraints: true},
+		tabla.nombresColumnas()...), nil
+}
+
+// Errores de Azure SQL / SQL Server que se reintentan: base no disponible
+// durante un failover (40613), servicio ocupado (40501), recursos
+// insuficientes (49918), límite de recursos de la base (10928) y víctima de
+// deadlock (1205). En todos el servidor deshace la transacción. Además se
+// reintenta la conexión perdida, lo más común durante un failover.
+var erroresTransitoriosSQLServer = map[int32]bool{
+	40613: true,
+	40501: true,
+	49918: true,
+	10928: true,
+	1205:  true,
+}
+
+func (sqlServer) transitorio(err error) bool {
+	var e mssql.Error
+	if !errors.As(err, &e) {
+		return conexionPerdida(err)
+	}
+	if erroresTransitoriosSQLServer[e.Number] {
+		return true
+	}
+	for _, previo := range e.All {
+		if erroresTransitoriosSQLServer[previo.Number] {
+			return true
+		}
+	}
+	return false
+}
+
+// ================== POSTGRESQL ==================
+// La conexión usa POSTGRES_URL si está definida; si no, lib/pq toma las
+// variables estándar PGHOST, PGPORT, PGUSER, PGPASSWORD y PGDATABASE.
+type postgres struct{}
+
+func (postgres) nombre() string { return DialectoPostgres }
+
+func (postgres) abrir() (*sql.DB, error) {
+	return sql.Open("postgres", os.Getenv("POSTGRES_URL"))
+}
+
+// identidad lee host y base de POSTGRES_URL, sea URL o "host=... dbname=...",
+// y si no los trae, de PGHOST y PGDATABASE.
+func (postgres) identidad(valor func(string) string) string {
+	host, base := valor("PGHOST"), valor("PGDATABASE")
+	dsn := strings.TrimSpace(valor("POSTGRES_URL"))
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		if u, err := url.Parse(dsn); err == nil {
+			if u.Hostname() != "" {
+				host = u.Hostname()
+			}
+			if b := strings.TrimPrefix(u.Path, "/"); b != "" {
+				base = b
+			}
+		}
+	} else {
+		for _, campo := range strings.Fields(dsn) {
+			k, v, _ := strings.Cut(campo, "=")
+			switch k {
+			case "host":
+				host = strings.Trim(v, "'")
+			case "dbname":
+				base = strings.Trim(v, "'")
+			}
+		}
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	return hostNormalizado(host) + "/" + base
+}
+
+func (postgres) marcador(n int) string { return fmt.Sprintf("$%d", n) }
+
+// Límite del protocolo extendido (uint16)
+func (postgres) maxParametros() int { return 65535 }
+
+// traducirDDL cambia IDENTITY por GENERATED y repite ADD en cada restricción
+// de los ALTER TABLE, además de las traducciones comunes.
+func (postgres) traducirDDL(script string) string {
+	s := traducirTiposTSQL(script, "PostgreSQL")
+	s = reIdentity.ReplaceAllString(s, "GENERATED BY DEFAULT AS IDENTITY")
+
+	// T-SQL admite "ALTER TABLE t ADD CONSTRAINT a ..., CONSTRAINT b ...";
+	// PostgreSQL exige repetir ADD en cada restricción.
+	return mapearSentencias(s, func(sentencia string) string {
+		if strings.HasPrefix(strings.ToUpper(sinComentarios(sentencia)), "ALTER TABLE") {
+			return reConstraintEn.ReplaceAllString(sentencia, ",${1}ADD CONSTRAINT")
+		}
+		return sentencia
+	})
+}
+
+// ejecutarLote envía el lote completo; lib/pq acepta varias sentencias
+// separadas por ';' cuando no hay parámetros.
+func (postgres) ejecutarLote(ctx context.Context, db *sql.DB, lote string) error {
+	_, err := db.ExecContext(ctx, lote)
+	return err
+}
+
+// copiaMasiva usa COPY FROM STDIN. pq.CopyIn cita los identificadores y el
+// esquema se crea sin comillas, así que los nombres van en minúsculas.
+func (postgres) copiaMasiva(tabla tablaSpec) (string, error) {
+	columnas := tabla.nombresColumnas()
+	for i, c := range columnas {
+		columnas[i] = strings.ToLower(c)
+	}
+	return pq.CopyIn(strings.ToLower(tabla.nombre), columnas...), nil
+}
+
+// Deadlock (40P01) y fallo de serialización (40001): PostgreSQL aborta la
+// transacción completa. También la conexión perdida, como en SQL Server.
+func (postgres) transitorio(err error) bool {
+	var e *pq.Error
+	if !errors.As(err, &e) {
+		return conexionPerdida(err)
+	}
+	return e.Code == "40P01" || e.Code == "40001"
+}
+
+// ================== SQLITE (EMBEBIDO) ==================
+// Base de datos en un archivo local (SQLITE_PATH, por defecto datawarehouse.db)
+// para desarrollo y CI sin servicios. Requiere cgo.
+type sqlite struct{}
+
+func (sqlite) nombre() string { return DialectoSQLite }
+
+func rutaSQLite(valor func(string) string) string {
+	if ruta := valor("SQLITE_PATH"); ruta != "" {
+		return ruta
+	}
+	return "datawarehouse.db"
+}
+
+func (sqlite) abrir() (*sql.DB, error) {
+	ruta := rutaSQLite(os.Getenv)
+	db, err := sql.Open("sqlite3", "file:"+ruta+"?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=10000")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite admite un solo escritor: las goroutines de la fase 1 esperan su
+	// turno por la conexión en lugar de fallar con "database is locked".
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// identidad es la ruta absoluta del archivo con los enlaces simbólicos
+// resueltos; si aún no existe, se resuelve su directorio.
+func (sqlite) identidad(valor func(string) string) string {
+	ruta, err := filepath.Abs(rutaSQLite(valor))
+	if err != nil {
+		return rutaSQLite(valor)
+	}
+	if real, err := filepath.EvalSymlinks(ruta); err == nil {
+		return real
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(ruta)); err == nil {
+		return filepath.Join(dir, filepath.Base(ruta))
+	}
+	return ruta
+}
+
+func (sqlite) marcador(n int) string { return fmt.Sprintf("?%d", n) }
+
+// SQLITE_MAX_VARIABLE_NUMBER desde SQLite 3.32
+func (sqlite) maxParametros() int { return 32766 }
+
+var reIdentityPK = regexp.MustCompile(`(?i)\b(BIG)?INT\s+IDENTITY\s*\(\s*1\s*,\s*1\s*\)\s+PRIMARY\s+KEY`)
+
+// traducirDDL convierte las columnas IDENTITY en INTEGER PRIMARY KEY
+// AUTOINCREMENT. SQLite no admite ALTER TABLE ADD CONSTRAINT, así que los
+// CHECK de calidad agregados al final del esquema se omiten.
+func (sqlite) traducirDDL(script string) string {
+	s := traducirTiposTSQL(script, "SQLite")
+	s = reIdentityPK.ReplaceAllString(s, "INTEGER PRIMARY KEY AUTOINCREMENT")
+	return mapearSentencias(s, func(sentencia string) string {
+		if strings.HasPrefix(strings.ToUpper(sinComentarios(sentencia)), "ALTER TABLE") {
+			return "\n-- (ALTER TABLE ADD CONSTRAINT omitido en SQLite)\n"
+		}
+		return sentencia
+	})
+}
+
+// ejecutarLote envía el lote completo; go-sqlite3 ejecuta todas las
+// sentencias del texto cuando no hay parámetros.
+func (sqlite) ejecutarLote(ctx context.Context, db *sql.DB, lote string) error {
+	_, err := db.ExecContext(ctx, lote)
+	return err
+}
+
+func (sqlite) insertarDevolviendoID(tabla, id string, columnas ...string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+		tabla, strings.Join(columnas, ", "), marcadores(sqlite{}, 1, len(columnas)), id)
+}
+
+// SQLite no tiene protocolo de copia masiva; el INSERT multi-fila ya es local.
+func (sqlite) copiaMasiva(tabla tablaSpec) (string, error) {
+	return "", fmt.Errorf("la carga %s no está disponible en %s", modoBulk, DialectoSQLite)
+}
+
+// La base bloqueada por otro proceso (SQLITE_BUSY, SQLITE_LOCKED) se
+// libera sola; _busy_timeout ya espera antes de devolverlo.
+func (sqlite) transitorio(err error) bool {
+	var e sqlite3.Error
+	return errors.As(err, &e) && (e.Code == sqlite3.ErrBusy || e.Code == sqlite3.ErrLocked)
+}
+
+// ================== TRADUCCIÓN DE DDL T-SQL ==================
+var (
+	reIfObjectID   = regexp.MustCompile(`(?i)IF\s+OBJECT_ID\([^)]*\)\s+IS\s+NULL\s+CREATE\s+TABLE`)
+	reIdentity     = regexp.MustCompile(`(?i)\bIDENTITY\s*\(\s*1\s*,\s*1\s*\)`)
+	reBitDefault   = regexp.MustCompile(`(?i)\bBIT\s+DEFAULT\s+([01])\b`)
+	reBit          = regexp.MustCompile(`(?i)\bBIT\b`)
+	reNVarchar     = regexp.MustCompile(`(?i)\bNVARCHAR\s*\(\s*(\d+|MAX)\s*\)`)
+	reDatetime2    = regexp.MustCompile(`(?i)\bDATETIME2\b`)
+	reColumnstore  = regexp.MustCompile(`(?im)^\s*CREATE\s+(CLUSTERED\s+|NONCLUSTERED\s+)?COLUMNSTORE\s+INDEX[^;]*;`)
+	reConstraintEn = regexp.MustCompile(`(?i),(\s*)CONSTRAINT\b`)
+)
+
+// traducirTiposTSQL aplica las traducciones comunes a PostgreSQL y SQLite:
+// BIT → BOOLEAN, NVARCHAR → TEXT, DATETIME2 → TIMESTAMP, IF OBJECT_ID →
+// CREATE TABLE IF NOT EXISTS. Los índices columnstore se omiten.
+func traducirTiposTSQL(script, motor string) string {
+	s := reIfObjectID.ReplaceAllString(script, "CREATE TABLE IF NOT EXISTS")
+	s = reBitDefault.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasSuffix(m, "1") {
+			return "BOOLEAN DEFAULT TRUE"
+		}
+		return "BOOLEAN DEFAULT FALSE"
+	})
+	s = reBit.ReplaceAllString(s, "BOOLEAN")
+	s = reNVarchar.ReplaceAllString(s, "TEXT")
+	s = reDatetime2.ReplaceAllString(s, "TIMESTAMP")
+	return reColumnstore.ReplaceAllString(s, "-- (índice columnstore omitido en "+motor+")")
+}
+
+// mapearSentencias aplica f a cada sentencia del script separada por ';'.
+func mapearSentencias(script string, f func(string) string) string {
+	sentencias := strings.Split(script, ";")
+	for i, sentencia := range sentencias {
+		sentencias[i] = f(sentencia)
+	}
+	return strings.Join(sentencias, ";")
+}
+
+// sinComentarios quita las líneas de comentario iniciales de una sentencia.
+func sinComentarios(sentencia string) string {
+	lineas := strings.Split(sentencia, "\n")
+	for len(lineas) > 0 {
+		l := strings.TrimSpace(lineas[0])
+		if l != "" && !strings.HasPrefix(l, "--") {
+			break
+		}
+		lineas = lineas[1:]
+	}
+	return strings.TrimSpace(strings.Join(lineas, "\n"))
+}
+
+func (postgres) insertarDevolviendoID(tabla, id string, columnas ...string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+		tabla, strings.Join(columnas, ", "), marcadores(postgres{}, 1, len(columnas)), id)
+}
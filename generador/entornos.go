@@ -0,0 +1,172 @@
+package generador
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ================== DESTINOS CON NOMBRE ==================
+// archivoEntornos declara los destinos que se eligen con -target. Cada uno
+// fija su dialecto y las variables de conexión, que se aplican sobre las del
+// .env; los protegidos no se limpian sin confirmación explícita.
+const archivoEntornos = "entornos.yaml"
+
+// Entorno es un destino con nombre de archivoEntornos.
+type Entorno struct {
+	Nombre    string            `yaml:"-"`
+	Dialecto  string            `yaml:"dialect"`
+	Protegido bool              `yaml:"protegido"`
+	Variables map[string]string `yaml:"variables"`
+}
+
+// cargarEntornos lee archivoEntornos; si no existe no hay destinos.
+func cargarEntornos() (map[string]Entorno, error) {
+	contenido, err := os.ReadFile(archivoEntornos)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("leyendo %s: %w", archivoEntornos, err)
+	}
+
+	var entornos map[string]Entorno
+	dec := yaml.NewDecoder(bytes.NewReader(contenido))
+	dec.KnownFields(true)
+	if err := dec.Decode(&entornos); err != nil {
+		return nil, fmt.Errorf("%s inválido: %w", archivoEntornos, err)
+	}
+	for nombre, e := range entornos {
+		e.Nombre = nombre
+		entornos[nombre] = e
+	}
+	return entornos, nil
+}
+
+// UsarEntorno aplica las variables del destino nombre al proceso y lo
+// devuelve; su Dialecto, si lo declara, es el que debe activarse.
+func UsarEntorno(nombre string) (Entorno, error) {
+	entornos, err := cargarEntornos()
+	if err != nil {
+		return Entorno{}, err
+	}
+	e, ok := entornos[nombre]
+	if !ok {
+		return Entorno{}, fmt.Errorf("destino %q no declarado en %s", nombre, archivoEntornos)
+	}
+	for k, v := range e.Variables {
+		os.Setenv(k, v)
+	}
+	if e.Protegido {
+		log.Printf("🎯 Destino: %s (protegido)", e.Nombre)
+	} else {
+		log.Printf("🎯 Destino: %s", e.Nombre)
+	}
+	return e, nil
+}
+
+// entornoProtegido busca el destino protegido al que apunta la conexión
+// actual: uno del dialecto d que lleve a la misma base, según la identidad
+// normalizada del dialecto. Así la guarda aplica aunque se llegue a
+// él por el .env, sin -target, o con las variables escritas de otra forma.
+func entornoProtegido(d dialecto) (Entorno, bool, error) {
+	entornos, err := cargarEntornos()
+	if err != nil {
+		return Entorno{}, false, err
+	}
+	nombres := make([]string, 0, len(entornos))
+	for nombre := range entornos {
+		nombres = append(nombres, nombre)
+	}
+	slices.Sort(nombres)
+
+	actual := d.identidad(os.Getenv)
+	for _, nombre := range nombres {
+		e := entornos[nombre]
+		if !e.Protegido || len(e.Variables) == 0 {
+			continue
+		}
+		if e.Dialecto != "" && e.Dialecto != d.nombre() {
+			continue
+		}
+		// Las variables que el destino no declara se toman del proceso
+		valor := func(k string) string {
+			if v, ok := e.Variables[k]; ok {
+				return v
+			}
+			return os.Getenv(k)
+		}
+		if d.identidad(valor) == actual {
+			return e, true, nil
+		}
+	}
+	return Entorno{}, false, nil
+}
+
+// destinoProtegido devuelve el nombre con que db quedó marcada en
+// Control_Destino, o "" si no está protegida. Si la conexión apunta a un
+// destino protegido de archivoEntornos y la base aún no tiene la marca, la
+// deja puesta.
+func (r *Corrida) destinoProtegido(ctx context.Context, db *sql.DB) (string, error) {
+	if err := r.asegurarTablasControl(ctx, db); err != nil {
+		return "", err
+	}
+	var marca sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT MIN(Nombre) FROM Control_Destino").Scan(&marca); err != nil {
+		return "", fmt.Errorf("leyendo Control_Destino: %w", err)
+	}
+	if marca.Valid {
+		return marca.String, nil
+	}
+
+	e, protegido, err := entornoProtegido(r.dialecto)
+	if err != nil || !protegido {
+		return "", err
+	}
+	_, err = db.ExecContext(ctx, fmt.Sprintf("INSERT INTO Control_Destino (Nombre, FechaMarca) VALUES (%s)",
+		marcadores(r.dialecto, 1, 2)), e.Nombre, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("marcando %s como protegido: %w", e.Nombre, err)
+	}
+	log.Printf("🔒 Base marcada como destino protegido %s", e.Nombre)
+	return e.Nombre, nil
+}
+
+// AutorizarLimpieza decide si LimpiarTablas puede vaciar la base db. En un
+// destino protegido exige que confirmacion sea su nombre y que todas las
+// filas del modelo las haya cargado el generador, según Control_Checkpoint;
+// con descartarAjenas también limpia las que no cargó, p. ej. en bases
+// pobladas antes de que existieran los puntos de control.
+func (r *Corrida) AutorizarLimpieza(ctx context.Context, db *sql.DB, confirmacion string, descartarAjenas bool) error {
+	nombre, err := r.destinoProtegido(ctx, db)
+	if err != nil || nombre == "" {
+		return err
+	}
+	if confirmacion != nombre {
+		return fmt.Errorf("el destino %s está protegido: confirme la limpieza con -confirm %s", nombre, nombre)
+	}
+
+	ajenas, err := r.tablasConFilasAjenas(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(ajenas) > 0 && descartarAjenas {
+		log.Printf("⚠️  %s tiene datos que no cargó el generador y se descartan:\n  %s",
+			nombre, strings.Join(ajenas, "\n  "))
+	} else if len(ajenas) > 0 {
+		return fmt.Errorf("el destino %s tiene datos que no cargó el generador; no se limpia "+
+			"(-discard-foreign los descarta):\n  %s",
+			nombre, strings.Join(ajenas, "\n  "))
+	}
+	log.Printf("🔓 Limpieza de %s confirmada", nombre)
+	return nil
+}
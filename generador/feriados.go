@@ -0,0 +1,114 @@
+package generador
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ================== FERIADOS DE COLOMBIA ==================
+// Calendario oficial: seis feriados de fecha fija, siete que la Ley 51 de
+// 1983 (Ley Emiliani) traslada al lunes siguiente y cinco que dependen de la
+// Pascua; de estos, Ascensión, Corpus Christi y Sagrado Corazón también se
+// trasladan al lunes. Se aplican las reglas vigentes a cualquier año.
+
+type feriado struct {
+	mes    time.Month
+	dia    int
+	nombre string
+}
+
+var feriadosFijos = []feriado{
+	{time.January, 1, "Año Nuevo"},
+	{time.May, 1, "Día del Trabajo"},
+	{time.July, 20, "Día de la Independencia"},
+	{time.August, 7, "Batalla de Boyacá"},
+	{time.December, 8, "Inmaculada Concepción"},
+	{time.December, 25, "Navidad"},
+}
+
+var feriadosEmiliani = []feriado{
+	{time.January, 6, "Reyes Magos"},
+	{time.March, 19, "San José"},
+	{time.June, 29, "San Pedro y San Pablo"},
+	{time.August, 15, "Asunción de la Virgen"},
+	{time.October, 12, "Día de la Raza"},
+	{time.November, 1, "Todos los Santos"},
+	{time.November, 11, "Independencia de Cartagena"},
+}
+
+// Días desde el domingo de Pascua; trasladable indica si aplica la Ley 51.
+var feriadosPascua = []struct {
+	dias        int
+	nombre      string
+	trasladable bool
+}{
+	{-3, "Jueves Santo", false},
+	{-2, "Viernes Santo", false},
+	{39, "Ascensión del Señor", true},
+	{60, "Corpus Christi", true},
+	{68, "Sagrado Corazón", true},
+}
+
+// feriadosDelAnio devuelve los feriados de anio por fecha (AAAA-MM-DD). Si
+// dos caen el mismo día (p. ej. Sagrado Corazón trasladado al lunes de San
+// Pedro) se unen los nombres.
+func feriadosDelAnio(anio int) map[string]string {
+	feriados := make(map[string]string, 18)
+	agregar := func(d time.Time, nombre string) {
+		clave := d.Format(time.DateOnly)
+		if previo, ok := feriados[clave]; ok {
+			nombre = previo + " / " + nombre
+		}
+		feriados[clave] = nombre
+	}
+
+	for _, f := range feriadosFijos {
+		agregar(time.Date(anio, f.mes, f.dia, 0, 0, 0, 0, time.UTC), f.nombre)
+	}
+	for _, f := range feriadosEmiliani {
+		agregar(lunesSiguiente(time.Date(anio, f.mes, f.dia, 0, 0, 0, 0, time.UTC)), f.nombre)
+	}
+	pascua := domingoDePascua(anio)
+	for _, f := range feriadosPascua {
+		d := pascua.AddDate(0, 0, f.dias)
+		if f.trasladable {
+			d = lunesSiguiente(d)
+		}
+		agregar(d, f.nombre)
+	}
+	return feriados
+}
+
+// lunesSiguiente traslada d al lunes siguiente; si ya es lunes no cambia.
+func lunesSiguiente(d time.Time) time.Time {
+	return d.AddDate(0, 0, (int(time.Monday)-int(d.Weekday())+7)%7)
+}
+
+// domingoDePascua calcula la Pascua del calendario gregoriano con el
+// algoritmo anónimo (Meeus/Jones/Butcher).
+func domingoDePascua(anio int) time.Time {
+	a := anio % 19
+	b, c := anio/100, anio%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	mes := (h + l - 7*m + 114) / 31
+	dia := (h+l-7*m+114)%31 + 1
+	return time.Date(anio, time.Month(mes), dia, 0, 0, 0, 0, time.UTC)
+}
+
+// asegurarNombreFeriado agrega NombreFeriado a los Dim_Tiempo creados antes
+// de que existiera; si el motor no lo permite, hay que recrear el esquema.
+func (r *Corrida) asegurarNombreFeriado(ctx context.Context, db *sql.DB) error {
+	err := r.asegurarColumna(ctx, db, tablaDimTiempo.nombre, "NombreFeriado", "NVARCHAR(50) NOT NULL DEFAULT ''")
+	if err != nil {
+		return fmt.Errorf("%w; recree el esquema (01_Esquema_Estrella.sql) sobre una base vacía", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package generador
+
+import (
+	"context"
+	"maps"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDomingoDePascua(t *testing.T) {
+	casos := map[int]string{
+		2000: "2000-04-23",
+		2019: "2019-04-21",
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2038: "2038-04-25", // la más tardía posible
+	}
+	for anio, esperada := range casos {
+		d := domingoDePascua(anio)
+		if got := d.Format(time.DateOnly); got != esperada {
+			t.Errorf("domingoDePascua(%d) = %s; se esperaba %s", anio, got, esperada)
+		}
+		if d.Weekday() != time.Sunday {
+			t.Errorf("domingoDePascua(%d) cae %s", anio, d.Weekday())
+		}
+	}
+}
+
+// Calendario oficial de Colombia para 2025: 18 feriados en 17 fechas, porque
+// Sagrado Corazón se traslada al lunes de San Pedro y San Pablo.
+func TestFeriadosDelAnio(t *testing.T) {
+	esperados := map[string]string{
+		"2025-01-01": "Año Nuevo",
+		"2025-01-06": "Reyes Magos",
+		"2025-03-24": "San José",
+		"2025-04-17": "Jueves Santo",
+		"2025-04-18": "Viernes Santo",
+		"2025-05-01": "Día del Trabajo",
+		"2025-06-02": "Ascensión del Señor",
+		"2025-06-23": "Corpus Christi",
+		"2025-06-30": "San Pedro y San Pablo / Sagrado Corazón",
+		"2025-07-20": "Día de la Independencia",
+		"2025-08-07": "Batalla de Boyacá",
+		"2025-08-18": "Asunción de la Virgen",
+		"2025-10-13": "Día de la Raza",
+		"2025-11-03": "Todos los Santos",
+		"2025-11-17": "Independencia de Cartagena",
+		"2025-12-08": "Inmaculada Concepción",
+		"2025-12-25": "Navidad",
+	}
+	if got := feriadosDelAnio(2025); !maps.Equal(got, esperados) {
+		t.Errorf("feriadosDelAnio(2025) = %v\nse esperaba %v", got, esperados)
+	}
+}
+
+func TestFeriadosDelAnioTrasladaAlLunes(t *testing.T) {
+	for anio := 2000; anio <= 2040; anio++ {
+		feriados := feriadosDelAnio(anio)
+		for _, f := range feriadosEmiliani {
+			d := lunesSiguiente(time.Date(anio, f.mes, f.dia, 0, 0, 0, 0, time.UTC))
+			if d.Weekday() != time.Monday {
+				t.Fatalf("%s %d trasladado a un %s", f.nombre, anio, d.Weekday())
+			}
+			if _, ok := feriados[d.Format(time.DateOnly)]; !ok {
+				t.Errorf("%s %d: falta el %s", f.nombre, anio, d.Format(time.DateOnly))
+			}
+		}
+		// Los fijos no se trasladan aunque caigan en domingo
+		for _, f := range feriadosFijos {
+			if _, ok := feriados[time.Date(anio, f.mes, f.dia, 0, 0, 0, 0, time.UTC).Format(time.DateOnly)]; !ok {
+				t.Errorf("%s %d: falta el %02d-%02d", f.nombre, anio, f.mes, f.dia)
+			}
+		}
+	}
+}
+
+// Un Dim_Tiempo creado antes de NombreFeriado recibe la columna al cargar,
+// sin perder sus filas.
+func TestAsegurarNombreFeriado(t *testing.T) {
+	t.Setenv("SQLITE_PATH", filepath.Join(t.TempDir(), "dw.db"))
+	r := &Corrida{config: configPredeterminada, dialecto: sqlite{}}
+	db, err := r.dialecto.abrir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "CREATE TABLE Dim_Tiempo (IDTiempo INT PRIMARY KEY, EsFeriado BOOLEAN)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO Dim_Tiempo VALUES (1, FALSE)"); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := r.asegurarNombreFeriado(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var nombre string
+	if err := db.QueryRowContext(ctx, "SELECT NombreFeriado FROM Dim_Tiempo WHERE IDTiempo = 1").Scan(&nombre); err != nil {
+		t.Fatal(err)
+	}
+	if nombre != "" {
+		t.Errorf("NombreFeriado = %q en una fila anterior; se esperaba vacío", nombre)
+	}
+}
@@ -0,0 +1,1036 @@
+// Package generador puebla el data warehouse de Cárnicos del Caribe: genera
+// las dimensiones y los hechos de forma determinista a partir de una Config y
+// los escribe en la base de datos, en archivos planos o en un plan de carga.
+//
+// Las funciones devuelven errores con contexto (tabla, lote, primera fila)
+// en lugar de terminar el proceso; quien las llama decide cómo reportarlos.
+// El estado de cada carga (configuración, dialecto, progreso, rendimiento)
+// viaja en una Corrida: el paquete no guarda estado global.
+package generador
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/denisenkom/go-mssqldb"
+)
+
+// ================== CONFIGURACIÓN ==================
+type Config struct {
+	VentasRecords       int `yaml:"ventas_records" json:"ventas_records"`
+	FinanzasYears       int `yaml:"finanzas_years" json:"finanzas_years"`
+	SatisfaccionRecords int `yaml:"satisfaccion_records" json:"satisfaccion_records"`
+	MetricasWebMonths   int `yaml:"metricas_web_months" json:"metricas_web_months"`
+	DimProductos        int `yaml:"dim_productos" json:"dim_productos"`
+	DimClientes         int `yaml:"dim_clientes" json:"dim_clientes"`
+	DimSucursales       int `yaml:"dim_sucursales" json:"dim_sucursales"`
+	DimEmpleados        int `yaml:"dim_empleados" json:"dim_empleados"`
+	DimTiempoAnios      int `yaml:"dim_tiempo_anios" json:"dim_tiempo_anios"`
+	BatchSize           int `yaml:"batch_size" json:"batch_size"`         // tope de filas por lote; 0 = automático
+	VentasWorkers       int `yaml:"ventas_workers" json:"ventas_workers"` // workers de Fact_Ventas; 0 = uno por CPU
+	MaxConexiones       int `yaml:"max_conexiones" json:"max_conexiones"` // conexiones abiertas a la vez; 0 = sin tope
+	Reintentos          int `yaml:"reintentos" json:"reintentos"`         // reintentos por transacción ante errores transitorios
+
+	// Factor aplicado a los volúmenes (SCALE_FACTOR); 1.0 = volumen completo
+	ScaleFactor float64 `yaml:"scale_factor" json:"scale_factor"`
+
+	// Semilla de la corrida; 0 = elegir una al azar y registrarla
+	Seed int64 `yaml:"seed" json:"seed"`
+
+	// Rango del dataset; FechaFin es la fecha ancla ("as of"). Si se omiten,
+	// FechaFin = hoy y FechaInicio = FechaFin - DimTiempoAnios.
+	FechaInicio Fecha `yaml:"fecha_inicio" json:"fecha_inicio"`
+	FechaFin    Fecha `yaml:"fecha_fin" json:"fecha_fin"`
+
+	// Método de carga por tabla: insert (por defecto), bulk o tvp; "*" aplica a
+	// todas las tablas que no tengan uno propio.
+	ModosCarga ModosCarga `yaml:"modos_carga" json:"modos_carga,omitempty"`
+
+	// Perfil del que se cargó la configuración (se registra en Control_Ejecucion)
+	Perfil string `yaml:"-" json:"perfil"`
+}
+
+// ================== CONFIGURACIÓN 1M EXACTO ==================
+var configPredeterminada = Config{
+	VentasRecords:       894_083, // 89.4% - Ajustado para 1M exacto
+	FinanzasYears:       3,
+	SatisfaccionRecords: 50_000, // 5.0%
+	MetricasWebMonths:   36,
+
+	DimProductos:   2_000,  // 0.2%
+	DimClientes:    50_000, // 5.0%
+	DimSucursales:  20,
+	DimEmpleados:   2_000, // 0.2%
+	DimTiempoAnios: 3,
+
+	BatchSize:     0, // Automático: cada tabla usa el máximo que permiten sus columnas
+	VentasWorkers: 4,
+	MaxConexiones: 8, // 4 workers de ventas + 3 hechos + Control_Ejecucion
+	Reintentos:    5, // Backoff de 1s a 30s: cubre un failover de Azure SQL
+
+	ScaleFactor: 1.0,
+
+	Perfil: perfilPredeterminado,
+}
+
+// ConfigPredeterminada devuelve los volúmenes compilados (1M de registros),
+// punto de partida de perfiles y flags.
+func ConfigPredeterminada() Config {
+	return configPredeterminada
+}
+
+// ================== CACHE DE TIEMPO ==================
+type TiempoCache struct {
+	mu    sync.RWMutex
+	cache map[string]int // fecha formato "2006-01-02" -> IDTiempo
+}
+
+func newTiempoCache() *TiempoCache {
+	return &TiempoCache{cache: make(map[string]int)}
+}
+
+func (tc *TiempoCache) Get(fecha time.Time) (int, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	id, ok := tc.cache[fecha.Format("2006-01-02")]
+	return id, ok
+}
+
+func (tc *TiempoCache) Set(fecha time.Time, id int) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.cache[fecha.Format("2006-01-02")] = id
+}
+
+// ================== UTILIDADES ==================
+func validarReferencias(nombre string, ids []int) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("no hay registros en %s para referenciar", nombre)
+	}
+	log.Printf("✓ %s: %d registros disponibles", nombre, len(ids))
+	return nil
+}
+
+// Distribución Pareto (80-20) para datos realistas
+func generarVentaPareto(rng *rand.Rand, min, max float64) float64 {
+	u := rng.Float64()
+	// Transformación inversa de Pareto con alpha=1.16 (aprox 80-20)
+	return min + (max-min)*math.Pow(u, 2.5)
+}
+
+// sumarMeses avanza n meses conservando el día, acotado al último día del mes
+// destino: AddDate normaliza el 31/10 + 1 mes a 01/12 y repetiría diciembre.
+func sumarMeses(t time.Time, n int) time.Time {
+	primero := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	ultimoDia := primero.AddDate(0, 1, -1).Day()
+	return primero.AddDate(0, 0, min(t.Day(), ultimoDia)-1)
+}
+
+// ================== FUNCIÓN BATCH INSERT CON TX ==================
+func insertBatchTx(ctx context.Context, tx *sql.Tx, d dialecto, table string, columns []string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	valueStrings := make([]string, len(rows))
+	valueArgs := make([]interface{}, 0, len(rows)*len(columns))
+
+	for i, row := range rows {
+		placeholders := make([]string, len(row))
+		for j := range row {
+			placeholders[j] = d.marcador(i*len(row) + j + 1)
+		}
+		valueStrings[i] = "(" + strings.Join(placeholders, ",") + ")"
+		valueArgs = append(valueArgs, row...)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		table, strings.Join(columns, ","), strings.Join(valueStrings, ","))
+
+	_, err := tx.ExecContext(ctx, query, valueArgs...)
+	return err
+}
+
+// ================== CONEXIÓN ==================
+// Conectar abre y verifica la conexión del dialecto de la corrida con a lo
+// sumo config.MaxConexiones conexiones abiertas (0 = sin tope).
+func (r *Corrida) Conectar(ctx context.Context) (*sql.DB, error) {
+	db, err := r.dialecto.abrir()
+	if err != nil {
+		return nil, fmt.Errorf("conexión a %s: %w", r.dialecto.nombre(), err)
+	}
+
+	// Tope global de conexiones: las cargas concurrentes esperan turno en el
+	// pool. Se respeta el límite que ya haya fijado el dialecto (SQLite usa 1).
+	// Las conexiones ociosas se conservan para no reabrirlas en cada commit.
+	if n := r.config.MaxConexiones; n > 0 && db.Stats().MaxOpenConnections == 0 {
+		db.SetMaxOpenConns(n)
+		db.SetMaxIdleConns(n)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("no se pudo conectar a %s: %w", r.dialecto.nombre(), err)
+	}
+	log.Printf("✅ Conectado a la base de datos (%s)", r.dialecto.nombre())
+	return db, nil
+}
+
+// ================== CARGAS CONCURRENTES ==================
+// cargasConcurrentes corre cargas en paralelo. El primer error cancela el
+// contexto de las demás y es el que devuelve esperar: los que siguen suelen
+// ser consecuencia de la cancelación.
+type cargasConcurrentes struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+func nuevasCargasConcurrentes(ctx context.Context) *cargasConcurrentes {
+	ctx, cancel := context.WithCancel(ctx)
+	return &cargasConcurrentes{ctx: ctx, cancel: cancel}
+}
+
+func (g *cargasConcurrentes) ir(carga func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := carga(g.ctx); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+func (g *cargasConcurrentes) esperar() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// ================== GENERACIÓN COMPLETA ==================
+// Generar puebla el modelo completo en dest con la configuración de la
+// corrida. Si una tabla falla, las cargas en curso se cancelan, lo no
+// confirmado se descarta y el log detalla qué tablas quedaron completas,
+// parciales o sin cargar.
+func (r *Corrida) Generar(ctx context.Context, dest Destino) (err error) {
+	r.rendimiento = &registroRendimiento{}
+	r.estado = &registroEstado{}
+	defer func() {
+		if err != nil {
+			r.estado.reportar()
+		}
+	}()
+
+	log.Printf("📊 Configuración: %d ventas, %d productos, %d clientes\n",
+		r.config.VentasRecords, r.config.DimProductos, r.config.DimClientes)
+
+	log.Printf("🎲 Semilla: %d (use -seed %d para reproducir este dataset)\n", r.config.Seed, r.config.Seed)
+	r.progreso.iniciar()
+	defer r.progreso.finalizar()
+	dims, err := r.poblarDimensiones(ctx, dest)
+	if err != nil {
+		return err
+	}
+
+	// ========== FASE 3: TABLAS DE HECHOS ==========
+	// Los hechos son independientes entre sí: solo leen las claves de las
+	// dimensiones y TiempoCache. El tope de conexiones del pool (MaxConexiones)
+	// limita cuántas cargas escriben a la vez.
+	log.Println("\n🔴 FASE 3: Poblando tablas de hechos...")
+	g := nuevasCargasConcurrentes(ctx)
+	g.ir(func(ctx context.Context) error {
+		return r.populateFactVentas(ctx, dest, dims.productoIDs, dims.clienteIDs, dims.sucursalIDs, dims.empleadoIDs,
+			dims.canalIDs, dims.estadoIDs, dims.tiempo)
+	})
+	g.ir(func(ctx context.Context) error {
+		return r.populateFactFinanzas(ctx, dest, dims.sucursalIDs, dims.tiempo)
+	})
+	g.ir(func(ctx context.Context) error {
+		return r.populateFactSatisfaccion(ctx, dest, dims.clienteIDs, dims.productoIDs, dims.sucursalIDs, dims.tiempo)
+	})
+	g.ir(func(ctx context.Context) error {
+		return r.populateFactMetricasWeb(ctx, dest, dims.canalIDs, dims.tiempo)
+	})
+	if err := g.esperar(); err != nil {
+		return err
+	}
+	if faltan := r.estado.incompletas(); len(faltan) > 0 {
+		return fmt.Errorf("la carga terminó sin confirmar %s", strings.Join(faltan, ", "))
+	}
+
+	r.progreso.finalizar()
+	r.registrarRendimientoEnLog()
+
+	log.Println("\n🎉 ¡GENERACIÓN COMPLETA DEL DATA WAREHOUSE!")
+	log.Printf("📈 Total aproximado: %d registros en todas las tablas\n",
+		r.config.VentasRecords+len(dims.tiempo.cache)*r.config.DimSucursales+
+			r.config.SatisfaccionRecords+r.config.MetricasWebMonths*len(dims.canalIDs))
+	return nil
+}
+
+// dimensiones guarda las claves cargadas que referencian las tablas de hechos.
+type dimensiones struct {
+	productoIDs, clienteIDs, sucursalIDs []int
+	canalIDs, estadoIDs, empleadoIDs     []int
+	tiempo                               *TiempoCache
+}
+
+// poblarDimensiones carga las fases 1 y 2 del modelo y valida sus claves.
+func (r *Corrida) poblarDimensiones(ctx context.Context, dest Destino) (dimensiones, error) {
+	nombres := nuevoVocabularioNombres(r.config.Seed)
+	dims := dimensiones{tiempo: newTiempoCache()}
+
+	// ========== FASE 1: DIMENSIONES INDEPENDIENTES ==========
+	log.Println("\n🔷 FASE 1: Poblando dimensiones independientes...")
+	g := nuevasCargasConcurrentes(ctx)
+	g.ir(func(ctx context.Context) (err error) {
+		dims.productoIDs, err = r.populateDimProductos(ctx, dest)
+		return err
+	})
+	g.ir(func(ctx context.Context) (err error) {
+		dims.clienteIDs, err = r.populateDimClientes(ctx, dest, nombres)
+		return err
+	})
+	g.ir(func(ctx context.Context) (err error) {
+		dims.sucursalIDs, err = r.populateDimSucursales(ctx, dest)
+		return err
+	})
+	g.ir(func(ctx context.Context) error {
+		return r.populateDimTiempo(ctx, dest, dims.tiempo)
+	})
+	if err := g.esperar(); err != nil {
+		return dims, err
+	}
+
+	// Validaciones
+	if err := errors.Join(
+		validarReferencias("Dim_Producto", dims.productoIDs),
+		validarReferencias("Dim_Cliente", dims.clienteIDs),
+		validarReferencias("Dim_Sucursal", dims.sucursalIDs),
+	); err != nil {
+		return dims, err
+	}
+	log.Printf("✓ Dim_Tiempo: %d registros en cache\n", len(dims.tiempo.cache))
+
+	// ========== FASE 2: DIMENSIONES DEPENDIENTES ==========
+	log.Println("\n🔶 FASE 2: Poblando dimensiones dependientes...")
+	var err error
+	if dims.canalIDs, err = r.populateDimCanales(ctx, dest); err != nil {
+		return dims, err
+	}
+	if dims.estadoIDs, err = r.populateDimEstados(ctx, dest); err != nil {
+		return dims, err
+	}
+	if dims.empleadoIDs, err = r.populateDimEmpleados(ctx, dest, dims.sucursalIDs, nombres); err != nil {
+		return dims, err
+	}
+
+	return dims, errors.Join(
+		validarReferencias("Dim_CanalVenta", dims.canalIDs),
+		validarReferencias("Dim_EstadoPedido", dims.estadoIDs),
+		validarReferencias("Dim_Empleado", dims.empleadoIDs),
+	)
+}
+
+// ================== DIM_TIEMPO CON CACHE ==================
+func (r *Corrida) populateDimTiempo(ctx context.Context, dest Destino, cache *TiempoCache) error {
+	log.Println("⏳ Poblando Dim_Tiempo...")
+
+	c, err := r.nuevoCargador(ctx, dest, tablaDimTiempo)
+	if err != nil {
+		return err
+	}
+	defer c.descartar()
+
+	start := r.config.FechaInicio.Time
+	end := r.config.FechaFin.Time
+
+	nombresMeses := []string{"Enero", "Febrero", "Marzo", "Abril", "Mayo", "Junio",
+		"Julio", "Agosto", "Septiembre", "Octubre", "Noviembre", "Diciembre"}
+	nombresDias := []string{"Domingo", "Lunes", "Martes", "Miércoles", "Jueves", "Viernes", "Sábado"}
+
+	// Feriados colombianos por año, calculados al entrar a cada uno
+	feriados := map[int]map[string]string{}
+	totalFeriados := 0
+
+	idCounter := 1
+
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		esFinDeSemana := d.Weekday() == time.Sunday || d.Weekday() == time.Saturday
+		if feriados[d.Year()] == nil {
+			feriados[d.Year()] = feriadosDelAnio(d.Year())
+		}
+		nombreFeriado, esFeriado := feriados[d.Year()][d.Format(time.DateOnly)]
+		if esFeriado {
+			totalFeriados++
+		}
+		_, semana := d.ISOWeek()
+		semestre := 1
+		if int(d.Month()) > 6 {
+			semestre = 2
+		}
+
+		if err := c.agregar(
+			idCounter, d, d.Year(), semestre,
+			(int(d.Month())-1)/3+1, // Trimestre
+			int(d.Month()),
+			nombresMeses[int(d.Month())-1],
+			d.Day(),
+			int(d.Weekday())+1, // 1=Domingo, 7=Sábado
+			nombresDias[d.Weekday()],
+			semana,
+			esFinDeSemana,
+			esFeriado,
+			fmt.Sprintf("Q%d-%d", (int(d.Month())-1)/3+1, d.Year()),
+			nombreFeriado,
+		); err != nil {
+			return err
+		}
+
+		// Guardar en cache
+		cache.Set(d, idCounter)
+		idCounter++
+	}
+
+	if err := c.cerrar(); err != nil {
+		return err
+	}
+	log.Printf("✔ Dim_Tiempo completada (%d días, %d feriados)\n", idCounter-1, totalFeriados)
+	return nil
+}
+
+// ================== DIM_PRODUCTO CON DISTRIBUCIÓN REALISTA ==================
+func (r *Corrida) populateDimProductos(ctx context.Context, dest Destino) ([]int, error) {
+	log.Println("📦 Poblando Dim_Producto...")
+	rng := nuevoRNG(r.config.Seed, "Dim_Producto")
+
+	c, err := r.nuevoCargador(ctx, dest, tablaDimProducto)
+	if err != nil {
+		return nil, err
+	}
+	defer c.descartar()
+
+	ids := make([]int, 0, r.config.DimProductos)
+	categorias := []string{"Frescos", "Procesados", "Marinos", "Embutidos"}
+	subcategorias := []string{"Premium", "Estándar", "Económico"}
+	marcas := []string{"DelCaribe", "FrescoMar", "CarnesSelectas", "Tradición"}
+
+	for i := 0; i < r.config.DimProductos; i++ {
+		// 80% de productos activos (Pareto)
+		activo := rng.Float64() < 0.8
+
+		if err := c.agregar(
+			i+1, // IDProducto
+			fmt.Sprintf("SKU-%06d", i+1),
+			fmt.Sprintf("Producto %s %d", categorias[i%len(categorias)], i+1),
+			categorias[i%len(categorias)], // Distribución equitativa
+			subcategorias[rng.IntN(len(subcategorias))],
+			marcas[rng.IntN(len(marcas))],
+			"Línea Principal",
+			activo,
+		); err != nil {
+			return nil, err
+		}
+		ids = append(ids, i+1)
+	}
+
+	if err := c.cerrar(); err != nil {
+		return nil, err
+	}
+	log.Printf("✔ Dim_Producto completada (%d registros)\n", r.config.DimProductos)
+	return ids, nil
+}
+
+// ================== DIM_CLIENTE CON SEGMENTACIÓN ==================
+func (r *Corrida) populateDimClientes(ctx context.Context, dest Destino, nombres *vocabularioNombres) ([]int, error) {
+	log.Println("👥 Poblando Dim_Cliente...")
+	rng := nuevoRNG(r.config.Seed, "Dim_Cliente")
+
+	c, err := r.nuevoCargador(ctx, dest, tablaDimCliente)
+	if err != nil {
+		return nil, err
+	}
+	defer c.descartar()
+
+	ids := make([]int, 0, r.config.DimClientes)
+	tipos := []string{"Minorista", "Mayorista", "Corporativo"}
+	ciudades := []string{"Cartagena", "Barranquilla", "Santa Marta", "Sincelejo", "Montería"}
+
+	for i := 0; i < r.config.DimClientes; i++ {
+		// Segmento A: 20%, B: 30%, C: 50%
+		var segmento string
+		prob := rng.Float64()
+		if prob < 0.2 {
+			segmento = "A"
+		} else if prob < 0.5 {
+			segmento = "B"
+		} else {
+			segmento = "C"
+		}
+
+		if err := c.agregar(
+			i+1, // IDCliente
+			fmt.Sprintf("CLI-%06d", i+1),
+			nombres.aleatorio(rng),
+			tipos[rng.IntN(len(tipos))],
+			segmento,
+			ciudades[rng.IntN(len(ciudades))],
+			"Caribe",
+			r.config.FechaFin.AddDate(-rng.IntN(5), -rng.IntN(12), -rng.IntN(28)),
+			rng.Float64() < 0.95, // 95% activos
+		); err != nil {
+			return nil, err
+		}
+		ids = append(ids, i+1)
+	}
+
+	if err := c.cerrar(); err != nil {
+		return nil, err
+	}
+	log.Printf("✔ Dim_Cliente completada (%d registros)\n", r.config.DimClientes)
+	return ids, nil
+}
+
+// ================== DIM_SUCURSAL ==================
+func (r *Corrida) populateDimSucursales(ctx context.Context, dest Destino) ([]int, error) {
+	log.Println("🏪 Poblando Dim_Sucursal...")
+	rng := nuevoRNG(r.config.Seed, "Dim_Sucursal")
+
+	c, err := r.nuevoCargador(ctx, dest, tablaDimSucursal)
+	if err != nil {
+		return nil, err
+	}
+	defer c.descartar()
+
+	ids := make([]int, 0, r.config.DimSucursales)
+	ciudades := []string{"Cartagena", "Barranquilla", "Santa Marta", "Sincelejo", "Montería"}
+	tipos := []string{"Tienda", "Supermercado", "Mayorista"}
+
+	for i := 0; i < r.config.DimSucursales; i++ {
+		ciudad := ciudades[i%len(ciudades)] // Distribución equitativa
+
+		if err := c.agregar(
+			i+1, // IDSucursal
+			fmt.Sprintf("SUC-%03d", i+1),
+			fmt.Sprintf("Sucursal %s %d", ciudad, (i/len(ciudades))+1),
+			fmt.Sprintf("Calle %d #%d-%d", rng.IntN(100)+1, rng.IntN(50)+1, rng.IntN(100)+1),
+			ciudad,
+			"Caribe",
+			tipos[rng.IntN(len(tipos))],
+			true,
+		); err != nil {
+			return nil, err
+		}
+		ids = append(ids, i+1)
+	}
+
+	if err := c.cerrar(); err != nil {
+		return nil, err
+	}
+	log.Printf("✔ Dim_Sucursal completada (%d registros)\n", r.config.DimSucursales)
+	return ids, nil
+}
+
+// ================== DIM_EMPLEADO NORMALIZADO (v3.1 - CORREGIDO) ==================
+func (r *Corrida) populateDimEmpleados(ctx context.Context, dest Destino, sucursalIDs []int, nombres *vocabularioNombres) ([]int, error) {
+	log.Println("👨‍💼 Poblando Dim_Empleado (estructura normalizada)...")
+	rng := nuevoRNG(r.config.Seed, "Dim_Empleado")
+
+	c, err := r.nuevoCargador(ctx, dest, tablaDimEmpleado)
+	if err != nil {
+		return nil, err
+	}
+	defer c.descartar()
+
+	ids := make([]int, 0, r.config.DimEmpleados)
+	cargos := []string{"Vendedor", "Cajero", "Repartidor", "Gerente", "Supervisor"}
+	departamentos := []string{"Ventas", "Operaciones", "Administración", "Logística"}
+
+	for i := 0; i < r.config.DimEmpleados; i++ {
+		if err := c.agregar(
+			i+1, // IDEmpleado
+			fmt.Sprintf("EMP-%05d", i+1),
+			nombres.aleatorio(rng),
+			cargos[rng.IntN(len(cargos))],
+			departamentos[rng.IntN(len(departamentos))],
+			sucursalIDs[rng.IntN(len(sucursalIDs))], // IDSucursal (FK)
+			r.config.FechaFin.AddDate(-rng.IntN(10), -rng.IntN(12), -rng.IntN(28)),
+			rng.Float64() < 0.92, // EmpleadoActivo
+		); err != nil {
+			return nil, err
+		}
+		ids = append(ids, i+1)
+	}
+
+	if err := c.cerrar(); err != nil {
+		return nil, err
+	}
+	log.Printf("✔ Dim_Empleado completada (%d registros)\n", r.config.DimEmpleados)
+	return ids, nil
+}
+
+// ================== DIM_CANALVENTA ==================
+func (r *Corrida) populateDimCanales(ctx context.Context, dest Destino) ([]int, error) {
+	log.Println("📱 Poblando Dim_CanalVenta...")
+
+	canales := []struct {
+		codigo string
+		nombre string
+		tipo   string
+	}{
+		{"TIENDA", "Venta en Tienda", "Físico"},
+		{"WEB", "Sitio Web", "Digital"},
+		{"MOVIL", "App Móvil", "Digital"},
+		{"MAYOR", "Venta Mayorista", "Físico"},
+	}
+
+	c, err := r.nuevoCargador(ctx, dest, tablaDimCanalVenta)
+	if err != nil {
+		return nil, err
+	}
+	defer c.descartar()
+
+	ids := make([]int, len(canales))
+
+	for i, canal := range canales {
+		if err := c.agregar(i+1, canal.codigo, canal.nombre, canal.tipo); err != nil {
+			return nil, err
+		}
+		ids[i] = i + 1
+	}
+
+	if err := c.cerrar(); err != nil {
+		return nil, err
+	}
+	log.Printf("✔ Dim_CanalVenta completada (%d registros)\n", len(canales))
+	return ids, nil
+}
+
+// ================== DIM_ESTADOPEDIDO ==================
+func (r *Corrida) populateDimEstados(ctx context.Context, dest Destino) ([]int, error) {
+	log.Println("📋 Poblando Dim_EstadoPedido...")
+
+	estados := []struct {
+		codigo string
+		desc   string
+		final  bool
+	}{
+		{"PEND", "Pendiente", false},
+		{"CONF", "Confirmado", false},
+		{"PREP", "En Preparación", false},
+		{"ENVI", "Enviado", false},
+		{"ENTR", "Entregado", true},
+		{"CANC", "Cancelado", true},
+	}
+
+	c, err := r.nuevoCargador(ctx, dest, tablaDimEstadoPedido)
+	if err != nil {
+		return nil, err
+	}
+	defer c.descartar()
+
+	ids := make([]int, len(estados))
+
+	for i, estado := range estados {
+		if err := c.agregar(i+1, estado.codigo, estado.desc, estado.final); err != nil {
+			return nil, err
+		}
+		ids[i] = i + 1
+	}
+
+	if err := c.cerrar(); err != nil {
+		return nil, err
+	}
+	log.Printf("✔ Dim_EstadoPedido completada (%d registros)\n", len(estados))
+	return ids, nil
+}
+
+// ================== FACT_VENTAS CON LOOKUP REAL ==================
+func (r *Corrida) populateFactVentas(ctx context.Context, dest Destino, productoIDs, clienteIDs,
+	sucursalIDs, empleadoIDs, canalIDs, estadoIDs []int, tiempoCache *TiempoCache) error {
+
+	bloques := (r.config.VentasRecords + bloqueVentas - 1) / bloqueVentas
+	var porCargar []int
+	for b := 0; b < bloques; b++ {
+		if !dest.confirmado(tablaFactVentas.nombre, b) {
+			porCargar = append(porCargar, b)
+		}
+	}
+	omitidos := bloques - len(porCargar)
+	if omitidos > 0 {
+		log.Printf("♻️  Fact_Ventas: %d de %d bloques ya confirmados", omitidos, bloques)
+	}
+	workers := min(r.trabajadoresVentas(dest), len(porCargar))
+	log.Printf("💰 Iniciando carga de %d ventas (%d bloques, %d workers)...\n",
+		r.config.VentasRecords, bloques, workers)
+
+	// Cada bloque guarda su total para sumarlos en orden al final
+	totales := make([]float64, bloques)
+
+	pendientes := make(chan int)
+	g := nuevasCargasConcurrentes(ctx)
+	for w := 0; w < workers; w++ {
+		g.ir(func(ctx context.Context) error {
+			// Una transacción por worker: cada bloque se confirma al tomar el
+			// siguiente y el último al cerrar
+			c, err := r.nuevoCargador(ctx, dest, tablaFactVentas)
+			if err != nil {
+				return err
+			}
+			defer c.descartar()
+			// Aunque no le toque ningún bloque, un worker nunca da por
+			// completa la tabla
+			c.porBloques = true
+
+			primero := true
+			for b := range pendientes {
+				if !primero {
+					if err := c.confirmar(); err != nil {
+						return err
+					}
+				}
+				primero = false
+
+				totales[b], err = r.generarBloqueVentas(c, b, productoIDs, clienteIDs, sucursalIDs,
+					empleadoIDs, canalIDs, estadoIDs, tiempoCache)
+				if err != nil {
+					return err
+				}
+			}
+			return c.cerrar()
+		})
+	}
+	// Si un worker falla, los demás dejan de recibir bloques
+repartir:
+	for _, b := range porCargar {
+		select {
+		case pendientes <- b:
+		case <-g.ctx.Done():
+			break repartir
+		}
+	}
+	close(pendientes)
+	if err := g.esperar(); err != nil {
+		return err
+	}
+	r.estado.confirmar(tablaFactVentas.nombre, 0, true)
+
+	totalVentas := 0.0
+	for _, t := range totales {
+		totalVentas += t
+	}
+	if omitidos > 0 {
+		log.Printf("✔ Fact_Ventas completado - Facturado en los bloques reanudados: $%.2f M\n", totalVentas/1000000)
+		return nil
+	}
+	log.Printf("✔ Fact_Ventas completado - Total facturado: $%.2f M\n", totalVentas/1000000)
+	return nil
+}
+
+// Ventas por bloque: unidad de reparto entre workers y de commit
+const bloqueVentas = 10000
+
+// trabajadoresVentas es la cantidad de workers de Fact_Ventas; los destinos
+// que no admiten varias escrituras sobre la misma tabla usan uno solo.
+func (r *Corrida) trabajadoresVentas(dest Destino) int {
+	if !dest.particionable() {
+		return 1
+	}
+	if r.config.VentasWorkers == 0 {
+		return runtime.NumCPU()
+	}
+	return r.config.VentasWorkers
+}
+
+// generarBloqueVentas genera las ventas [b*bloqueVentas, (b+1)*bloqueVentas)
+// con un flujo aleatorio propio del bloque, así que el resultado depende de la
+// semilla pero no de cuántos workers haya ni de qué worker tome cada bloque.
+// Devuelve el total facturado del bloque.
+func (r *Corrida) generarBloqueVentas(c *cargador, b int, productoIDs, clienteIDs, sucursalIDs,
+	empleadoIDs, canalIDs, estadoIDs []int, tiempoCache *TiempoCache) (float64, error) {
+
+	fuente := nuevaFuente(r.config.Seed, fmt.Sprintf("Fact_Ventas/%d", b))
+	rng := rand.New(fuente)
+	start := r.config.FechaInicio.Time
+	diasRango := r.config.diasRango()
+	totalVentas := 0.0
+	ultimoPedido := ""
+
+	for i := b * bloqueVentas; i < min((b+1)*bloqueVentas, r.config.VentasRecords); i++ {
+		// Generar fechas coherentes
+		fechaVenta := start.AddDate(0, 0, rng.IntN(diasRango))
+		fechaPedido := fechaVenta.AddDate(0, 0, -rng.IntN(3))   // 0-2 días antes
+		fechaEntrega := fechaVenta.AddDate(0, 0, rng.IntN(5)+1) // 1-5 días después
+
+		// Buscar IDs desde cache - si no existen, usar la fecha de venta
+		idTiempoVenta, ok := tiempoCache.Get(fechaVenta)
+		if !ok {
+			continue // Saltar si la fecha no está en cache
+		}
+
+		idTiempoPedido, ok := tiempoCache.Get(fechaPedido)
+		if !ok {
+			idTiempoPedido = idTiempoVenta // Usar fecha de venta si pedido no está
+		}
+
+		idTiempoEntrega, ok := tiempoCache.Get(fechaEntrega)
+		if !ok {
+			idTiempoEntrega = idTiempoVenta // Usar fecha de venta si entrega no está
+		}
+
+		// Generar precios con distribución Pareto
+		costo := generarVentaPareto(rng, 30, 150)
+		margen := 1.2 + rng.Float64()*0.8 // Margen 20%-100%
+		precio := costo * margen
+		descuento := precio * (rng.Float64() * 0.15) // Hasta 15% descuento
+		cantidad := rng.IntN(20) + 1
+
+		totalVentas += (precio - descuento) * float64(cantidad)
+
+		// NumeroPedido sale del índice global: cada bloque tiene su rango
+		ultimoPedido = fmt.Sprintf("PED-%08d", i+1)
+		if err := c.agregar(
+			ultimoPedido,
+			idTiempoVenta, idTiempoPedido, idTiempoEntrega,
+			productoIDs[rng.IntN(len(productoIDs))],
+			clienteIDs[rng.IntN(len(clienteIDs))],
+			sucursalIDs[rng.IntN(len(sucursalIDs))],
+			empleadoIDs[rng.IntN(len(empleadoIDs))],
+			canalIDs[rng.IntN(len(canalIDs))],
+			estadoIDs[rng.IntN(len(estadoIDs))],
+			cantidad, precio, costo, descuento,
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	// El punto de control del bloque se confirma junto con sus filas
+	estado, err := fuente.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	err = c.marcarPunto(puntoControl{
+		tabla:       tablaFactVentas.nombre,
+		bloque:      b,
+		ultimaClave: ultimoPedido,
+		estadoRNG:   hex.EncodeToString(estado),
+	})
+	return totalVentas, err
+}
+
+// ================== FACT_FINANZAS MENSUAL ==================
+func (r *Corrida) populateFactFinanzas(ctx context.Context, dest Destino, sucursalIDs []int, tiempoCache *TiempoCache) error {
+	log.Println("💵 Cargando registros financieros mensuales...")
+	rng := nuevoRNG(r.config.Seed, "Fact_Finanzas")
+
+	c, err := r.nuevoCargador(ctx, dest, tablaFactFinanzas)
+	if err != nil {
+		return err
+	}
+	defer c.descartar()
+
+	start := r.config.FechaFin.AddDate(-r.config.FinanzasYears, 0, 0)
+
+	// Generar un registro financiero por mes por sucursal
+	for mes := 0; mes < r.config.FinanzasYears*12; mes++ {
+		fechaMes := sumarMeses(start, mes)
+		idTiempo, ok := tiempoCache.Get(fechaMes)
+
+		if !ok {
+			// Si no existe esa fecha exacta, buscar el primer día del mes
+			primerDia := time.Date(fechaMes.Year(), fechaMes.Month(), 1, 0, 0, 0, 0, time.UTC)
+			if idTiempo, ok = tiempoCache.Get(primerDia); !ok {
+				return fmt.Errorf("%s: el mes %s no está en Dim_Tiempo", tablaFactFinanzas.nombre, fechaMes.Format("2006-01"))
+			}
+		}
+
+		for _, idSucursal := range sucursalIDs {
+			// Generar métricas financieras realistas
+			ventasBase := float64(500000 + rng.IntN(500000))
+
+			// Variación estacional (más ventas en Diciembre, menos en Enero)
+			factorEstacional := 1.0
+			switch fechaMes.Month() {
+			case 12: // Diciembre
+				factorEstacional = 1.5
+			case 1: // Enero
+				factorEstacional = 0.7
+			case 6, 7: // Mitad de año
+				factorEstacional = 1.2
+			}
+
+			ventas := ventasBase * factorEstacional
+			costos := ventas * (0.60 + rng.Float64()*0.15) // 60-75% de costos
+			gastos := ventas * 0.15                        // 15% gastos operativos
+			utilidadBruta := ventas - costos
+			utilidadNeta := utilidadBruta - gastos
+			margen := (utilidadBruta / ventas) * 100
+
+			if err := c.agregar(
+				idTiempo, idSucursal, ventas, costos, gastos,
+				utilidadBruta, utilidadNeta, margen,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := c.cerrar(); err != nil {
+		return err
+	}
+	totalRegistros := r.config.FinanzasYears * 12 * len(sucursalIDs)
+	log.Printf("✔ Fact_Finanzas completado (%d registros mensuales)\n", totalRegistros)
+	return nil
+}
+
+// ================== FACT_SATISFACCION CON DISTRIBUCIÓN NORMAL ==================
+func (r *Corrida) populateFactSatisfaccion(ctx context.Context, dest Destino, clienteIDs, productoIDs,
+	sucursalIDs []int, tiempoCache *TiempoCache) error {
+
+	log.Printf("⭐ Generando %d encuestas de satisfacción...\n", r.config.SatisfaccionRecords)
+	rng := nuevoRNG(r.config.Seed, "Fact_SatisfaccionCliente")
+
+	c, err := r.nuevoCargador(ctx, dest, tablaFactSatisfaccion)
+	if err != nil {
+		return err
+	}
+	defer c.descartar()
+
+	// Últimos 2 años, o todo el rango si el dataset es más corto
+	start := r.config.FechaFin.AddDate(-2, 0, 0)
+	if start.Before(r.config.FechaInicio.Time) {
+		start = r.config.FechaInicio.Time
+	}
+	diasEncuestas := int(r.config.FechaFin.Sub(start).Hours() / 24)
+
+	for i := 0; i < r.config.SatisfaccionRecords; i++ {
+		fecha := start.AddDate(0, 0, rng.IntN(diasEncuestas))
+		idTiempo, ok := tiempoCache.Get(fecha)
+
+		if !ok {
+			continue // Saltar si no hay fecha en cache
+		}
+
+		// Generar puntuaciones con sesgo positivo (distribución normal centrada en 8)
+		puntuacionServicio := generarPuntuacionNPS(rng, 8.0, 1.5)
+		puntuacionProducto := generarPuntuacionNPS(rng, 7.5, 1.8)
+		puntuacionGeneral := (puntuacionServicio + puntuacionProducto) / 2
+
+		// Probabilidad de recomendar correlacionada con puntuación general
+		recomendaria := puntuacionGeneral >= 7.0
+
+		if err := c.agregar(
+			idTiempo,
+			sucursalIDs[rng.IntN(len(sucursalIDs))],
+			clienteIDs[rng.IntN(len(clienteIDs))],
+			productoIDs[rng.IntN(len(productoIDs))],
+			puntuacionServicio,
+			puntuacionProducto,
+			int(puntuacionGeneral),
+			recomendaria,
+		); err != nil {
+			return err
+		}
+	}
+
+	if err := c.cerrar(); err != nil {
+		return err
+	}
+	log.Printf("✔ Fact_SatisfaccionCliente completado (%d registros)\n", r.config.SatisfaccionRecords)
+	return nil
+}
+
+// Función auxiliar para generar puntuaciones NPS realistas
+func generarPuntuacionNPS(rng *rand.Rand, media, desviacion float64) int {
+	// Box-Muller transform para distribución normal
+	u1 := rng.Float64()
+	u2 := rng.Float64()
+	z := math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+	puntuacion := media + z*desviacion
+
+	// Limitar entre 1 y 10
+	if puntuacion < 1 {
+		return 1
+	}
+	if puntuacion > 10 {
+		return 10
+	}
+	return int(puntuacion)
+}
+
+// ================== FACT_METRICAS_WEB CON TENDENCIAS ==================
+func (r *Corrida) populateFactMetricasWeb(ctx context.Context, dest Destino, _ []int, tiempoCache *TiempoCache) error {
+	log.Printf("🌐 Generando métricas web para %d meses...\n", r.config.MetricasWebMonths)
+	rng := nuevoRNG(r.config.Seed, "Fact_MetricasWeb")
+
+	c, err := r.nuevoCargador(ctx, dest, tablaFactMetricasWeb)
+	if err != nil {
+		return err
+	}
+	defer c.descartar()
+
+	start := r.config.primerMesMetricasWeb()
+	totalRegistros := 0
+
+	// Solo canales digitales
+	canalesDigitales := []int{2, 3} // WEB y MOVIL
+
+	for mes := 0; mes < r.config.MetricasWebMonths; mes++ {
+		primerDia := start.AddDate(0, mes, 0)
+		idTiempo, ok := tiempoCache.Get(primerDia)
+
+		if !ok {
+			return fmt.Errorf("%s: el mes %s no está en Dim_Tiempo", tablaFactMetricasWeb.nombre, primerDia.Format("2006-01"))
+		}
+
+		for _, idCanal := range canalesDigitales {
+			// Tendencia creciente: más tráfico en meses recientes
+			factorCrecimiento := 1.0 + (float64(mes) / float64(r.config.MetricasWebMonths) * 0.5)
+
+			sesionesBase := rng.IntN(5000) + 2000
+			sesiones := int(float64(sesionesBase) * factorCrecimiento)
+
+			// Usuarios únicos: 60-80% de sesiones
+			usuarios := int(float64(sesiones) * (0.6 + rng.Float64()*0.2))
+
+			// Tasa de conversión: 2-8%
+			tasaConversionBase := 0.02 + rng.Float64()*0.06
+			conversiones := int(float64(sesiones) * tasaConversionBase)
+
+			// Ingresos por conversión: $20-$200
+			ticketPromedio := float64(rng.IntN(180) + 20)
+			ingresos := float64(conversiones) * ticketPromedio
+
+			tasaConversion := (float64(conversiones) / float64(sesiones)) * 100
+
+			if err := c.agregar(
+				idTiempo, idCanal, sesiones, usuarios, conversiones,
+				tasaConversion, ingresos,
+			); err != nil {
+				return err
+			}
+			totalRegistros++
+		}
+	}
+
+	if err := c.cerrar(); err != nil {
+		return err
+	}
+	log.Printf("✔ Fact_MetricasWeb completado (%d registros mensuales)\n", totalRegistros)
+	return nil
+}
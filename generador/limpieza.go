@@ -0,0 +1,296 @@
+package generador
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ================== FUNCIÓN DE LIMPIEZA ==================
+// LimpiarTablas vacía las tablas del modelo y los puntos de control, que sin
+// los datos ya no sirven para reanudar. El orden sale de las claves foráneas
+// del catálogo (hijas antes que padres) y cada dialecto usa TRUNCATE donde
+// puede; si no, se borra con DELETE en ese orden. Todo ocurre en una sola
+// transacción y al final se verifica que las tablas quedaron vacías.
+func (r *Corrida) LimpiarTablas(ctx context.Context, db *sql.DB) error {
+	if err := r.asegurarTablasControl(ctx, db); err != nil {
+		return err
+	}
+	claves, err := r.dialecto.clavesForaneas(ctx, db)
+	if err != nil {
+		return fmt.Errorf("leyendo claves foráneas: %w", err)
+	}
+	orden, err := ordenLimpieza(claves)
+	if err != nil {
+		return err
+	}
+	orden = append([]string{"Control_Checkpoint"}, orden...)
+
+	inicio := time.Now()
+	metodo := "TRUNCATE"
+	if err := r.dialecto.truncar(ctx, db, orden, claves); err != nil {
+		if !errors.Is(err, errSinTruncate) {
+			log.Printf("⚠️  TRUNCATE no disponible (%v); se borra con DELETE", err)
+		}
+		metodo = "DELETE"
+		if err := borrarEnOrden(ctx, db, orden); err != nil {
+			return err
+		}
+	}
+	if err := verificarVacias(ctx, db, orden); err != nil {
+		return err
+	}
+	for _, t := range orden {
+		log.Printf("✔ %s limpiada", t)
+	}
+	log.Printf("✅ Limpieza completada con %s en %s", metodo, time.Since(inicio).Round(time.Millisecond))
+	return nil
+}
+
+// claveForanea es una restricción FOREIGN KEY entre dos tablas del modelo.
+// Las columnas y las acciones solo las llena el dialecto que necesita
+// recrearla.
+type claveForanea struct {
+	nombre      string
+	tabla       string // la que referencia (hija)
+	referencia  string // la referenciada (padre)
+	columnas    []string
+	columnasRef []string
+	alBorrar    string // p. ej. "NO ACTION", "CASCADE"
+	alActualiz  string
+}
+
+// errSinTruncate indica que el dialecto no tiene TRUNCATE.
+var errSinTruncate = errors.New("el motor no tiene TRUNCATE")
+
+// nombreEnModelo devuelve el nombre canónico de una tabla del catálogo si
+// pertenece al modelo; PostgreSQL las devuelve en minúsculas.
+func nombreEnModelo(nombre string) (string, bool) {
+	for _, t := range tablasModelo {
+		if strings.EqualFold(t.nombre, nombre) {
+			return t.nombre, true
+		}
+	}
+	return "", false
+}
+
+// ordenLimpieza ordena las tablas del modelo para vaciarlas: cada tabla va
+// después de todas las que la referencian. Entre las que quedan libres a la
+// vez se respeta el orden inverso de carga, así el resultado es estable.
+func ordenLimpieza(claves []claveForanea) ([]string, error) {
+	hijas := map[string]map[string]bool{}
+	for _, fk := range claves {
+		if fk.tabla == fk.referencia {
+			continue
+		}
+		if hijas[fk.referencia] == nil {
+			hijas[fk.referencia] = map[string]bool{}
+		}
+		hijas[fk.referencia][fk.tabla] = true
+	}
+
+	pendientes := make([]string, 0, len(tablasModelo))
+	for _, t := range slices.Backward(tablasModelo) {
+		pendientes = append(pendientes, t.nombre)
+	}
+	var orden []string
+	for len(pendientes) > 0 {
+		libre := slices.IndexFunc(pendientes, func(t string) bool { return len(hijas[t]) == 0 })
+		if libre < 0 {
+			return nil, fmt.Errorf("las claves foráneas entre %s forman un ciclo", strings.Join(pendientes, ", "))
+		}
+		t := pendientes[libre]
+		pendientes = slices.Delete(pendientes, libre, libre+1)
+		orden = append(orden, t)
+		for _, h := range hijas {
+			delete(h, t)
+		}
+	}
+	return orden, nil
+}
+
+// borrarEnOrden vacía las tablas con DELETE en una transacción: si una falla
+// (p. ej. la referencia una tabla ajena al modelo) no se borra nada.
+func borrarEnOrden(ctx context.Context, db *sql.DB, orden []string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, t := range orden {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+t); err != nil {
+			return fmt.Errorf("limpiando %s: %w", t, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("confirmando la limpieza: %w", err)
+	}
+	return nil
+}
+
+// verificarVacias comprueba que la limpieza no dejó filas.
+func verificarVacias(ctx context.Context, db *sql.DB, tablas []string) error {
+	var conFilas []string
+	for _, t := range tablas {
+		var n int64
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t).Scan(&n); err != nil {
+			return fmt.Errorf("contando %s: %w", t, err)
+		}
+		if n > 0 {
+			conFilas = append(conFilas, fmt.Sprintf("%s (%d)", t, n))
+		}
+	}
+	if len(conFilas) > 0 {
+		return fmt.Errorf("la limpieza dejó filas en %s", strings.Join(conFilas, ", "))
+	}
+	return nil
+}
+
+// ================== LIMPIEZA EN SQL SERVER ==================
+// SQL Server no trunca una tabla referenciada por una clave foránea, aunque
+// la hija esté vacía: se quitan las claves entre tablas del modelo, se
+// trunca y se recrean, todo en la transacción. Si algo falla, el rollback
+// deja las claves y los datos como estaban.
+func (sqlServer) clavesForaneas(ctx context.Context, db *sql.DB) ([]claveForanea, error) {
+	rows, err := db.QueryContext(ctx, `SELECT fk.name,
+		OBJECT_NAME(fk.parent_object_id), OBJECT_NAME(fk.referenced_object_id),
+		COL_NAME(fkc.parent_object_id, fkc.parent_column_id),
+		COL_NAME(fkc.referenced_object_id, fkc.referenced_column_id),
+		fk.delete_referential_action_desc, fk.update_referential_action_desc
+	FROM sys.foreign_keys fk
+	JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+	ORDER BY fk.name, fkc.constraint_column_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claves []claveForanea
+	for rows.Next() {
+		var fk claveForanea
+		var columna, columnaRef string
+		if err := rows.Scan(&fk.nombre, &fk.tabla, &fk.referencia, &columna, &columnaRef,
+			&fk.alBorrar, &fk.alActualiz); err != nil {
+			return nil, err
+		}
+		hija, ok1 := nombreEnModelo(fk.tabla)
+		padre, ok2 := nombreEnModelo(fk.referencia)
+		if !ok1 || !ok2 {
+			continue
+		}
+		// Una fila por columna: las de una misma clave llegan seguidas
+		if n := len(claves); n > 0 && claves[n-1].nombre == fk.nombre {
+			claves[n-1].columnas = append(claves[n-1].columnas, columna)
+			claves[n-1].columnasRef = append(claves[n-1].columnasRef, columnaRef)
+			continue
+		}
+		fk.tabla, fk.referencia = hija, padre
+		fk.columnas, fk.columnasRef = []string{columna}, []string{columnaRef}
+		fk.alBorrar = strings.ReplaceAll(fk.alBorrar, "_", " ")
+		fk.alActualiz = strings.ReplaceAll(fk.alActualiz, "_", " ")
+		claves = append(claves, fk)
+	}
+	return claves, rows.Err()
+}
+
+func (sqlServer) truncar(ctx context.Context, db *sql.DB, orden []string, claves []claveForanea) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, fk := range claves {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s",
+			corchetes(fk.tabla), corchetes(fk.nombre))); err != nil {
+			return fmt.Errorf("quitando %s: %w", fk.nombre, err)
+		}
+	}
+	for _, t := range orden {
+		if _, err := tx.ExecContext(ctx, "TRUNCATE TABLE "+corchetes(t)); err != nil {
+			return fmt.Errorf("truncando %s: %w", t, err)
+		}
+	}
+	for _, fk := range claves {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"ALTER TABLE %s WITH CHECK ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s) ON DELETE %s ON UPDATE %s",
+			corchetes(fk.tabla), corchetes(fk.nombre), listaCorchetes(fk.columnas),
+			corchetes(fk.referencia), listaCorchetes(fk.columnasRef), fk.alBorrar, fk.alActualiz)); err != nil {
+			return fmt.Errorf("recreando %s: %w", fk.nombre, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func corchetes(nombre string) string {
+	return "[" + strings.ReplaceAll(nombre, "]", "]]") + "]"
+}
+
+func listaCorchetes(nombres []string) string {
+	citados := make([]string, len(nombres))
+	for i, n := range nombres {
+		citados[i] = corchetes(n)
+	}
+	return strings.Join(citados, ", ")
+}
+
+// ================== LIMPIEZA EN POSTGRESQL ==================
+// Un solo TRUNCATE con todas las tablas resuelve las claves entre ellas sin
+// quitarlas. Sin CASCADE: si una tabla ajena al modelo referencia alguna,
+// falla y se pasa a DELETE, que solo borra si esa tabla no tiene filas
+// que dependan del modelo.
+func (postgres) clavesForaneas(ctx context.Context, db *sql.DB) ([]claveForanea, error) {
+	rows, err := db.QueryContext(ctx, `SELECT conname, conrelid::regclass::text, confrelid::regclass::text
+		FROM pg_constraint WHERE contype = 'f'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return clavesDelModelo(rows)
+}
+
+func (postgres) truncar(ctx context.Context, db *sql.DB, orden []string, _ []claveForanea) error {
+	_, err := db.ExecContext(ctx, "TRUNCATE TABLE "+strings.Join(orden, ", ")+" RESTART IDENTITY")
+	return err
+}
+
+// ================== LIMPIEZA EN SQLITE ==================
+// SQLite no tiene TRUNCATE; un DELETE sin WHERE ya vacía la tabla sin
+// recorrerla fila por fila.
+func (sqlite) clavesForaneas(ctx context.Context, db *sql.DB) ([]claveForanea, error) {
+	rows, err := db.QueryContext(ctx, `SELECT 'fk_' || m.name || '_' || p.id, m.name, p."table"
+		FROM sqlite_master m, pragma_foreign_key_list(m.name) p WHERE m.type = 'table'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return clavesDelModelo(rows)
+}
+
+func (sqlite) truncar(context.Context, *sql.DB, []string, []claveForanea) error {
+	return errSinTruncate
+}
+
+// clavesDelModelo lee filas (nombre, tabla, referencia) y se queda con las
+// claves entre tablas del modelo.
+func clavesDelModelo(rows *sql.Rows) ([]claveForanea, error) {
+	var claves []claveForanea
+	for rows.Next() {
+		var fk claveForanea
+		if err := rows.Scan(&fk.nombre, &fk.tabla, &fk.referencia); err != nil {
+			return nil, err
+		}
+		hija, ok1 := nombreEnModelo(fk.tabla)
+		padre, ok2 := nombreEnModelo(fk.referencia)
+		if ok1 && ok2 {
+			fk.tabla, fk.referencia = hija, padre
+			claves = append(claves, fk)
+		}
+	}
+	return claves, rows.Err()
+}
@@ -0,0 +1,80 @@
+package generador
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// clavesDelEsquema son las claves foráneas de 01_Esquema_Estrella.sql, una por
+// par hija-padre.
+var clavesDelEsquema = []claveForanea{
+	{tabla: "Dim_Empleado", referencia: "Dim_Sucursal"},
+	{tabla: "Fact_Ventas", referencia: "Dim_Tiempo"},
+	{tabla: "Fact_Ventas", referencia: "Dim_Producto"},
+	{tabla: "Fact_Ventas", referencia: "Dim_Cliente"},
+	{tabla: "Fact_Ventas", referencia: "Dim_Sucursal"},
+	{tabla: "Fact_Ventas", referencia: "Dim_Empleado"},
+	{tabla: "Fact_Ventas", referencia: "Dim_CanalVenta"},
+	{tabla: "Fact_Ventas", referencia: "Dim_EstadoPedido"},
+	{tabla: "Fact_Finanzas", referencia: "Dim_Tiempo"},
+	{tabla: "Fact_Finanzas", referencia: "Dim_Sucursal"},
+	{tabla: "Fact_SatisfaccionCliente", referencia: "Dim_Tiempo"},
+	{tabla: "Fact_SatisfaccionCliente", referencia: "Dim_Sucursal"},
+	{tabla: "Fact_SatisfaccionCliente", referencia: "Dim_Cliente"},
+	{tabla: "Fact_SatisfaccionCliente", referencia: "Dim_Producto"},
+	{tabla: "Fact_MetricasWeb", referencia: "Dim_Tiempo"},
+	{tabla: "Fact_MetricasWeb", referencia: "Dim_CanalVenta"},
+}
+
+func TestOrdenLimpiezaHijasAntesQuePadres(t *testing.T) {
+	orden, err := ordenLimpieza(clavesDelEsquema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orden) != len(tablasModelo) {
+		t.Fatalf("ordenLimpieza devolvió %d tablas; el modelo tiene %d: %v", len(orden), len(tablasModelo), orden)
+	}
+	for _, tabla := range tablasModelo {
+		if !slices.Contains(orden, tabla.nombre) {
+			t.Errorf("falta %s en %v", tabla.nombre, orden)
+		}
+	}
+	for _, fk := range clavesDelEsquema {
+		if slices.Index(orden, fk.tabla) > slices.Index(orden, fk.referencia) {
+			t.Errorf("%s se vacía después de %s, a la que referencia: %v", fk.tabla, fk.referencia, orden)
+		}
+	}
+}
+
+func TestOrdenLimpiezaSinClavesEsInversoDeCarga(t *testing.T) {
+	orden, err := ordenLimpieza(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tabla := range slices.Backward(tablasModelo) {
+		if orden[len(tablasModelo)-1-i] != tabla.nombre {
+			t.Fatalf("sin claves se esperaba el orden inverso de carga; se obtuvo %v", orden)
+		}
+	}
+}
+
+func TestOrdenLimpiezaIgnoraAutoreferencias(t *testing.T) {
+	claves := append(slices.Clone(clavesDelEsquema), claveForanea{tabla: "Dim_Empleado", referencia: "Dim_Empleado"})
+	if _, err := ordenLimpieza(claves); err != nil {
+		t.Fatalf("una clave de la tabla consigo misma no es un ciclo: %v", err)
+	}
+}
+
+func TestOrdenLimpiezaCiclo(t *testing.T) {
+	claves := append(slices.Clone(clavesDelEsquema), claveForanea{tabla: "Dim_Sucursal", referencia: "Dim_Empleado"})
+	orden, err := ordenLimpieza(claves)
+	if err == nil {
+		t.Fatalf("se esperaba error por el ciclo Dim_Empleado <-> Dim_Sucursal; se obtuvo %v", orden)
+	}
+	for _, tabla := range []string{"Dim_Empleado", "Dim_Sucursal", "forman un ciclo"} {
+		if !strings.Contains(err.Error(), tabla) {
+			t.Errorf("el error no menciona %q: %v", tabla, err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package generador
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ================== PERFILES DE CONFIGURACIÓN ==================
+const (
+	perfilPredeterminado = "predeterminado"
+	dirPerfiles          = "perfiles"
+)
+
+var extensionesPerfil = []string{".yaml", ".yml", ".json"}
+
+// rutaPerfil resuelve un perfil por nombre (buscado en perfiles/) o por ruta.
+func rutaPerfil(nombre string) (string, error) {
+	if strings.ContainsAny(nombre, `/\`) || filepath.Ext(nombre) != "" {
+		return nombre, nil
+	}
+	for _, ext := range extensionesPerfil {
+		ruta := filepath.Join(dirPerfiles, nombre+ext)
+		if _, err := os.Stat(ruta); err == nil {
+			return ruta, nil
+		}
+	}
+	return "", fmt.Errorf("perfil %q no encontrado en %s/ (%s)",
+		nombre, dirPerfiles, strings.Join(extensionesPerfil, ", "))
+}
+
+// CargarPerfil lee un perfil YAML o JSON. Los perfiles deben ser completos:
+// se parte de una Config vacía para que ningún valor dependa del binario.
+func CargarPerfil(nombre string) (Config, error) {
+	ruta, err := rutaPerfil(nombre)
+	if err != nil {
+		return Config{}, err
+	}
+
+	contenido, err := os.ReadFile(ruta)
+	if err != nil {
+		return Config{}, fmt.Errorf("leyendo perfil: %w", err)
+	}
+
+	var cfg Config
+	switch strings.ToLower(filepath.Ext(ruta)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(contenido))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&cfg)
+	default:
+		dec := yaml.NewDecoder(bytes.NewReader(contenido))
+		dec.KnownFields(true)
+		err = dec.Decode(&cfg)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("perfil %s inválido: %w", ruta, err)
+	}
+
+	cfg.Perfil = strings.TrimSuffix(filepath.Base(ruta), filepath.Ext(ruta))
+	return cfg, nil
+}
+
+// ValidarConfig revisa que la configuración pueda generar un modelo coherente.
+func ValidarConfig(cfg Config) error {
+	var errs []error
+
+	positivos := []struct {
+		campo string
+		valor int
+	}{
+		{"ventas_records", cfg.VentasRecords},
+		{"finanzas_years", cfg.FinanzasYears},
+		{"satisfaccion_records", cfg.SatisfaccionRecords},
+		{"metricas_web_months", cfg.MetricasWebMonths},
+		{"dim_productos", cfg.DimProductos},
+		{"dim_clientes", cfg.DimClientes},
+		{"dim_sucursales", cfg.DimSucursales},
+		{"dim_empleados", cfg.DimEmpleados},
+		{"dim_tiempo_anios", cfg.DimTiempoAnios},
+	}
+	for _, p := range positivos {
+		if p.valor <= 0 {
+			errs = append(errs, fmt.Errorf("%s debe ser mayor que 0 (actual: %d)", p.campo, p.valor))
+		}
+	}
+
+	if cfg.ScaleFactor <= 0 {
+		errs = append(errs, fmt.Errorf("scale_factor debe ser mayor que 0 (actual: %g)", cfg.ScaleFactor))
+	}
+	if cfg.VentasWorkers < 0 {
+		errs = append(errs, fmt.Errorf("ventas_workers no puede ser negativo (actual: %d; 0 = uno por CPU)", cfg.VentasWorkers))
+	}
+	if cfg.MaxConexiones < 0 {
+		errs = append(errs, fmt.Errorf("max_conexiones no puede ser negativo (actual: %d; 0 = sin tope)", cfg.MaxConexiones))
+	}
+	if cfg.Reintentos < 0 {
+		errs = append(errs, fmt.Errorf("reintentos no puede ser negativo (actual: %d; 0 = sin reintentos)", cfg.Reintentos))
+	}
+	if cfg.BatchSize < 0 {
+		errs = append(errs, fmt.Errorf("batch_size no puede ser negativo (actual: %d; 0 = automático)", cfg.BatchSize))
+	}
+	if !cfg.FechaInicio.Before(cfg.FechaFin.Time) {
+		errs = append(errs, fmt.Errorf("fecha_inicio (%s) debe ser anterior a fecha_fin (%s)",
+			cfg.FechaInicio, cfg.FechaFin))
+	}
+	if cfg.FechaFin.AddDate(-cfg.FinanzasYears, 0, 0).Before(cfg.FechaInicio.Time) {
+		errs = append(errs, fmt.Errorf("finanzas_years (%d) excede el rango %s..%s",
+			cfg.FinanzasYears, cfg.FechaInicio, cfg.FechaFin))
+	}
+	if cfg.primerMesMetricasWeb().Before(cfg.FechaInicio.Time) {
+		errs = append(errs, fmt.Errorf("metricas_web_months (%d) excede el rango %s..%s",
+			cfg.MetricasWebMonths, cfg.FechaInicio, cfg.FechaFin))
+	}
+
+	if err := cfg.ModosCarga.validar(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// ================== FACTOR DE ESCALA ==================
+// EscalarConfig aplica cfg.ScaleFactor a los volúmenes. Sucursales, canales,
+// estados y los rangos de tiempo son fijos: definen la forma del modelo, no
+// su tamaño.
+func EscalarConfig(cfg Config) Config {
+	if cfg.ScaleFactor == 1 {
+		return cfg
+	}
+	cfg.VentasRecords = escalar(cfg.VentasRecords, cfg.ScaleFactor)
+	cfg.SatisfaccionRecords = escalar(cfg.SatisfaccionRecords, cfg.ScaleFactor)
+	cfg.DimProductos = escalar(cfg.DimProductos, cfg.ScaleFactor)
+	cfg.DimClientes = escalar(cfg.DimClientes, cfg.ScaleFactor)
+	cfg.DimEmpleados = escalar(cfg.DimEmpleados, cfg.ScaleFactor)
+	return cfg
+}
+
+// escalar redondea n*factor con un mínimo de 1 registro. test-suite usa la
+// misma fórmula para ajustar sus expectativas.
+func escalar(n int, factor float64) int {
+	v := int(math.Round(float64(n) * factor))
+	if v < 1 {
+		return 1
+	}
+	return v
+}
+
+// ================== FECHAS DEL DATASET ==================
+const formatoFecha = "2006-01-02"
+
+// Fecha es un día de calendario en UTC que se lee y escribe como "2006-01-02"
+// en perfiles, flags y Control_Ejecucion.
+type Fecha struct {
+	time.Time
+}
+
+func parseFecha(s string) (Fecha, error) {
+	t, err := time.Parse(formatoFecha, strings.TrimSpace(s))
+	if err != nil {
+		return Fecha{}, fmt.Errorf("fecha %q inválida (formato %s)", s, formatoFecha)
+	}
+	return Fecha{t}, nil
+}
+
+func hoy() Fecha {
+	y, m, d := time.Now().Date()
+	return Fecha{time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f Fecha) String() string {
+	if f.IsZero() {
+		return ""
+	}
+	return f.Format(formatoFecha)
+}
+
+// Set implementa flag.Value.
+func (f *Fecha) Set(s string) error {
+	v, err := parseFecha(s)
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+func (f *Fecha) UnmarshalYAML(n *yaml.Node) error {
+	return f.Set(n.Value)
+}
+
+func (f Fecha) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+func (f *Fecha) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*f = Fecha{}
+		return nil
+	}
+	return f.Set(s)
+}
+
+// ResolverFechas completa el rango del dataset a partir de la fecha ancla.
+func ResolverFechas(cfg *Config) {
+	if cfg.FechaFin.IsZero() {
+		cfg.FechaFin = hoy()
+	}
+	if cfg.FechaInicio.IsZero() {
+		cfg.FechaInicio = Fecha{cfg.FechaFin.AddDate(-cfg.DimTiempoAnios, 0, 0)}
+	}
+}
+
+// primerMesMetricasWeb devuelve el día 1 del primer mes de Fact_MetricasWeb:
+// son los MetricasWebMonths meses que terminan en el de FechaFin.
+func (cfg Config) primerMesMetricasWeb() time.Time {
+	ultimoMes := time.Date(cfg.FechaFin.Year(), cfg.FechaFin.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return ultimoMes.AddDate(0, 1-cfg.MetricasWebMonths, 0)
+}
+
+// diasRango devuelve los días entre FechaInicio y FechaFin.
+func (cfg Config) diasRango() int {
+	return int(cfg.FechaFin.Sub(cfg.FechaInicio.Time).Hours() / 24)
+}
@@ -0,0 +1,47 @@
+package generador
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rangoSmoke es el rango de perfiles/smoke.yaml: un año que termina un día 31.
+func rangoSmoke(meses int) Config {
+	cfg := configPredeterminada
+	cfg.FinanzasYears = 1
+	cfg.MetricasWebMonths = meses
+	cfg.FechaInicio = Fecha{time.Date(2024, 10, 31, 0, 0, 0, 0, time.UTC)}
+	cfg.FechaFin = Fecha{time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)}
+	return cfg
+}
+
+func TestPrimerMesMetricasWeb(t *testing.T) {
+	cfg := rangoSmoke(12)
+	if got := cfg.primerMesMetricasWeb().Format(time.DateOnly); got != "2024-11-01" {
+		t.Errorf("primerMesMetricasWeb = %s; se esperaba 2024-11-01 (12 meses hasta octubre de 2025)", got)
+	}
+	if err := ValidarConfig(cfg); err != nil {
+		t.Errorf("12 meses caben en el rango: %v", err)
+	}
+}
+
+func TestValidarConfigMetricasWebFueraDeRango(t *testing.T) {
+	// El 1 de octubre de 2024 queda antes de fecha_inicio
+	err := ValidarConfig(rangoSmoke(13))
+	if err == nil || !strings.Contains(err.Error(), "metricas_web_months (13)") {
+		t.Errorf("se esperaba rechazar 13 meses en el rango: %v", err)
+	}
+}
+
+func TestEscalarConfigMinimoUno(t *testing.T) {
+	cfg := configPredeterminada
+	cfg.ScaleFactor = 0.000001
+	cfg = EscalarConfig(cfg)
+	if cfg.VentasRecords != 1 || cfg.DimEmpleados != 1 {
+		t.Errorf("ventas/empleados = %d/%d; se esperaba el mínimo de 1", cfg.VentasRecords, cfg.DimEmpleados)
+	}
+	if cfg.DimSucursales != configPredeterminada.DimSucursales || cfg.MetricasWebMonths != configPredeterminada.MetricasWebMonths {
+		t.Errorf("sucursales y meses no deben escalarse: %+v", cfg)
+	}
+}
@@ -0,0 +1,160 @@
+package generador
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+)
+
+// ================== PLAN DE CARGA (DRY-RUN) ==================
+// PlanCarga es un destino que no escribe nada: contabiliza por tabla las filas,
+// sentencias, parámetros y commits que haría una carga real.
+type PlanCarga struct {
+	mu     sync.Mutex
+	tablas map[string]*planTabla
+}
+
+// planTabla es compartida por todos los sinks de la tabla; modo y lote son
+// los que usaría la corrida que la abrió.
+type planTabla struct {
+	mu            sync.Mutex
+	tabla         tablaSpec
+	modo          string
+	lote          int
+	Filas         int
+	Sentencias    int
+	MaxParametros int
+	Commits       int
+	Bytes         int64
+}
+
+// NuevoPlanCarga crea un plan vacío; se llena al pasarlo a Generar.
+func NuevoPlanCarga() *PlanCarga {
+	return &PlanCarga{tablas: make(map[string]*planTabla)}
+}
+
+func (p *PlanCarga) particionable() bool { return true }
+
+// El plan siempre cuenta la carga completa.
+func (p *PlanCarga) confirmado(string, int) bool { return false }
+
+func (p *PlanCarga) abrir(_ context.Context, r *Corrida, t tablaSpec) (sink, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pt, ok := p.tablas[t.nombre]
+	if !ok {
+		modo := r.config.ModosCarga.modo(t.nombre)
+		pt = &planTabla{tabla: t, modo: modo, lote: r.filasPorLote(t, modo)}
+		p.tablas[t.nombre] = pt
+	}
+	return pt, nil
+}
+
+func (pt *planTabla) escribir(filas [][]interface{}) error {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.Filas += len(filas)
+	pt.Sentencias++
+	// bulk no usa parámetros y tvp envía el lote en uno solo
+	params := 0
+	switch pt.metodo() {
+	case modoInsert:
+		params = len(filas) * len(pt.tabla.columnas)
+	case modoTVP:
+		params = 1
+	}
+	if params > pt.MaxParametros {
+		pt.MaxParametros = params
+	}
+	for _, fila := range filas {
+		for i, v := range fila {
+			pt.Bytes += int64(bytesValor(pt.tabla.columnas[i].tipo, v))
+		}
+	}
+	return nil
+}
+
+func (pt *planTabla) confirmar() error {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.Commits++
+	return nil
+}
+
+func (pt *planTabla) cerrar() error {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.Commits++
+	return nil
+}
+
+func (pt *planTabla) descartar() {}
+
+func (pt *planTabla) metodo() string { return pt.modo }
+
+// bytesValor estima el almacenamiento de un valor según su tipo en SQL Server.
+func bytesValor(tipo string, v interface{}) int {
+	switch {
+	case tipo == "INT":
+		return 4
+	case tipo == "BIGINT":
+		return 8
+	case tipo == "DATE":
+		return 3
+	case tipo == "BIT":
+		return 1
+	case strings.HasPrefix(tipo, "DECIMAL(5,"):
+		return 5
+	case strings.HasPrefix(tipo, "DECIMAL"):
+		return 9
+	case strings.HasPrefix(tipo, "NVARCHAR"):
+		if s, ok := v.(string); ok {
+			return 2*utf8.RuneCountInString(s) + 2
+		}
+	}
+	return 8
+}
+
+// Imprimir muestra el plan en el orden de carga del modelo; duracion es el
+// tiempo que tomó calcularlo.
+func (p *PlanCarga) Imprimir(w io.Writer, duracion time.Duration) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Tabla\tMétodo\tFilas\tBatch\tParám./sentencia\tSentencias\tCommits\tVolumen est.\t")
+
+	var filas, sentencias, commits int
+	var bytes int64
+	for _, t := range tablasModelo {
+		pt, ok := p.tablas[t.nombre]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\t\n", t.nombre, pt.metodo(), pt.Filas, pt.lote,
+			pt.MaxParametros, pt.Sentencias, pt.Commits, formatearBytes(pt.Bytes))
+		filas += pt.Filas
+		sentencias += pt.Sentencias
+		commits += pt.Commits
+		bytes += pt.Bytes
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t%d\t\t\t%d\t%d\t%s\t\n", filas, sentencias, commits, formatearBytes(bytes))
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nPlan calculado en %s sin tocar la base de datos.\n", duracion.Round(time.Millisecond))
+}
+
+func formatearBytes(b int64) string {
+	const unidad = 1024
+	if b < unidad {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unidad), 0
+	for n := b / unidad; n >= unidad; n /= unidad {
+		div *= unidad
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGT"[exp])
+}
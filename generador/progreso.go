@@ -0,0 +1,308 @@
+package generador
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ================== PROGRESO DE LA CARGA ==================
+// monitorProgreso sigue las filas escritas por tabla (los escritores de cada
+// cargador suman al terminar cada lote) y las muestra con filas/s, porcentaje,
+// tiempo transcurrido y ETA:
+//   - tty:  un bloque que se redibuja al pie de la terminal; los mensajes de
+//     log se imprimen por encima sin romperlo.
+//   - json: una línea JSON por tabla activa cada intervaloProgresoJSON en
+//     stdout, y un resumen por tabla al terminar.
+const (
+	ProgresoAuto = "auto"
+	ProgresoTTY  = "tty"
+	ProgresoJSON = "json"
+	ProgresoOff  = "off"
+
+	intervaloProgresoTTY  = 500 * time.Millisecond
+	intervaloProgresoJSON = 5 * time.Second
+)
+
+// avanceTabla es el progreso de una tabla; los workers paralelos de una misma
+// tabla comparten el suyo.
+type avanceTabla struct {
+	nombre string
+	total  int64
+	filas  atomic.Int64
+	inicio time.Time
+
+	// protegidos por monitorProgreso.mu
+	abiertos int
+	fin      time.Time
+}
+
+func (a *avanceTabla) sumar(n int) { a.filas.Add(int64(n)) }
+
+// estadoAvance es una foto del progreso; también es la línea JSON.
+type estadoAvance struct {
+	Evento        string  `json:"evento"`
+	Tabla         string  `json:"tabla"`
+	Filas         int64   `json:"filas"`
+	Total         int64   `json:"total,omitempty"`
+	Porcentaje    float64 `json:"porcentaje,omitempty"`
+	FilasPorSeg   float64 `json:"filas_por_seg"`
+	TranscurridoS float64 `json:"transcurrido_s"`
+	ETAS          float64 `json:"eta_s,omitempty"`
+	Momento       string  `json:"momento"`
+}
+
+func (a *avanceTabla) estado(ahora time.Time) estadoAvance {
+	terminada := !a.fin.IsZero()
+	if terminada {
+		ahora = a.fin
+	}
+	transcurrido := ahora.Sub(a.inicio)
+	e := estadoAvance{
+		Evento:        "progreso",
+		Tabla:         a.nombre,
+		Filas:         a.filas.Load(),
+		Total:         a.total,
+		TranscurridoS: math.Round(transcurrido.Seconds()*1000) / 1000,
+		Momento:       ahora.Format(time.RFC3339),
+	}
+	if transcurrido > 0 {
+		e.FilasPorSeg = math.Round(float64(e.Filas) / transcurrido.Seconds())
+	}
+	if e.Total > 0 {
+		e.Porcentaje = math.Round(min(float64(e.Filas)/float64(e.Total)*100, 100)*10) / 10
+		if !terminada && e.FilasPorSeg > 0 && e.Filas < e.Total {
+			e.ETAS = math.Ceil(float64(e.Total-e.Filas) / e.FilasPorSeg)
+		}
+	}
+	return e
+}
+
+type monitorProgreso struct {
+	mu     sync.Mutex
+	modo   string
+	tablas []*avanceTabla
+	lineas int       // líneas del bloque dibujado en la terminal
+	salida io.Writer // salida original del log (tty)
+	json   io.Writer
+
+	detener chan struct{}
+	listo   chan struct{}
+}
+
+// UsarProgreso fija el modo del progreso de las cargas de la corrida; auto
+// elige tty si stderr es una terminal y json si no.
+func (r *Corrida) UsarProgreso(modo string) error {
+	switch modo {
+	case ProgresoAuto:
+		modo = ProgresoJSON
+		if fi, err := os.Stderr.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			modo = ProgresoTTY
+		}
+	case ProgresoTTY, ProgresoJSON, ProgresoOff:
+	default:
+		return fmt.Errorf("modo de progreso inválido %q (use %s, %s, %s o %s)", modo,
+			ProgresoAuto, ProgresoTTY, ProgresoJSON, ProgresoOff)
+	}
+	r.progreso.modo = modo
+	return nil
+}
+
+// iniciar arranca el refresco periódico; no hace nada con el modo off.
+func (m *monitorProgreso) iniciar() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tablas = nil
+	if m.modo == ProgresoOff || m.detener != nil {
+		return
+	}
+
+	intervalo := intervaloProgresoJSON
+	if m.modo == ProgresoTTY {
+		intervalo = intervaloProgresoTTY
+		m.salida = log.Writer()
+		log.SetOutput(salidaConProgreso{m})
+	} else {
+		m.json = os.Stdout
+	}
+
+	m.detener = make(chan struct{})
+	m.listo = make(chan struct{})
+	go func() {
+		defer close(m.listo)
+		t := time.NewTicker(intervalo)
+		defer t.Stop()
+		for {
+			select {
+			case <-m.detener:
+				return
+			case <-t.C:
+				m.refrescar()
+			}
+		}
+	}()
+}
+
+// finalizar detiene el refresco, borra el bloque de la terminal y, en modo
+// json, emite el resumen por tabla.
+func (m *monitorProgreso) finalizar() {
+	if m.detener == nil {
+		return
+	}
+	close(m.detener)
+	<-m.listo
+	m.detener = nil
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch m.modo {
+	case ProgresoTTY:
+		m.borrar()
+		log.SetOutput(m.salida)
+	case ProgresoJSON:
+		for _, a := range m.tablas {
+			e := a.estado(time.Now())
+			e.Evento = "resumen"
+			m.emitir(e)
+		}
+	}
+}
+
+// abrirTabla registra un sink de la tabla; total es el número esperado de
+// filas (0 si no se conoce).
+func (m *monitorProgreso) abrirTabla(nombre string, total int) *avanceTabla {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, a := range m.tablas {
+		if a.nombre == nombre && a.fin.IsZero() {
+			a.abiertos++
+			return a
+		}
+	}
+	a := &avanceTabla{nombre: nombre, total: int64(total), inicio: time.Now(), abiertos: 1}
+	m.tablas = append(m.tablas, a)
+	return a
+}
+
+// cerrarTabla marca el fin de un sink; la tabla termina con el último.
+func (m *monitorProgreso) cerrarTabla(a *avanceTabla) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if a.abiertos--; a.abiertos == 0 {
+		a.fin = time.Now()
+		if m.modo == ProgresoJSON {
+			e := a.estado(a.fin)
+			e.Evento = "fin"
+			m.emitir(e)
+		}
+	}
+}
+
+func (m *monitorProgreso) refrescar() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch m.modo {
+	case ProgresoTTY:
+		m.borrar()
+		m.dibujar()
+	case ProgresoJSON:
+		ahora := time.Now()
+		for _, a := range m.tablas {
+			if a.fin.IsZero() {
+				m.emitir(a.estado(ahora))
+			}
+		}
+	}
+}
+
+func (m *monitorProgreso) emitir(e estadoAvance) {
+	linea, _ := json.Marshal(e)
+	fmt.Fprintf(m.json, "%s\n", linea)
+}
+
+// dibujar escribe una línea por tabla en curso al pie de la terminal.
+func (m *monitorProgreso) dibujar() {
+	var b strings.Builder
+	ahora := time.Now()
+	for _, a := range m.tablas {
+		if !a.fin.IsZero() {
+			continue
+		}
+		e := a.estado(ahora)
+		porcentaje, eta := "   ?  ", "?"
+		if e.Total > 0 {
+			porcentaje = fmt.Sprintf("%5.1f%%", e.Porcentaje)
+			if e.FilasPorSeg > 0 {
+				eta = (time.Duration(e.ETAS) * time.Second).String()
+			}
+		}
+		fmt.Fprintf(&b, "⏳ %-25s %s  %9d filas  %8.0f filas/s  %8v  ETA %s\n",
+			e.Tabla, porcentaje, e.Filas, e.FilasPorSeg,
+			time.Duration(e.TranscurridoS*float64(time.Second)).Round(time.Second), eta)
+		m.lineas++
+	}
+	io.WriteString(m.salida, b.String())
+}
+
+// borrar sube el cursor al inicio del bloque y limpia hasta el final.
+func (m *monitorProgreso) borrar() {
+	if m.lineas > 0 {
+		fmt.Fprintf(m.salida, "\033[%dA\033[J", m.lineas)
+		m.lineas = 0
+	}
+}
+
+// salidaConProgreso imprime cada mensaje de log por encima del bloque de
+// progreso y lo vuelve a dibujar debajo.
+type salidaConProgreso struct {
+	m *monitorProgreso
+}
+
+func (s salidaConProgreso) Write(p []byte) (int, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	s.m.borrar()
+	n, err := s.m.salida.Write(p)
+	s.m.dibujar()
+	return n, err
+}
+
+// filasEsperadas es el volumen que la configuración pide para cada tabla;
+// las dimensiones fijas y pequeñas devuelven 0 (sin porcentaje ni ETA). Al
+// reanudar, Fact_Ventas descuenta los bloques que dest ya tiene confirmados.
+func (r *Corrida) filasEsperadas(dest Destino, tabla tablaSpec) int {
+	switch tabla.nombre {
+	case tablaDimTiempo.nombre:
+		return r.config.diasRango() + 1
+	case tablaDimProducto.nombre:
+		return r.config.DimProductos
+	case tablaDimCliente.nombre:
+		return r.config.DimClientes
+	case tablaDimSucursal.nombre:
+		return r.config.DimSucursales
+	case tablaDimEmpleado.nombre:
+		return r.config.DimEmpleados
+	case tablaFactVentas.nombre:
+		filas := r.config.VentasRecords
+		for b := 0; b*bloqueVentas < r.config.VentasRecords; b++ {
+			if dest.confirmado(tabla.nombre, b) {
+				filas -= min(bloqueVentas, r.config.VentasRecords-b*bloqueVentas)
+			}
+		}
+		return filas
+	case tablaFactFinanzas.nombre:
+		return r.config.FinanzasYears * 12 * r.config.DimSucursales
+	case tablaFactSatisfaccion.nombre:
+		return r.config.SatisfaccionRecords
+	case tablaFactMetricasWeb.nombre:
+		return r.config.MetricasWebMonths * 2 // canales digitales
+	}
+	return 0
+}
@@ -0,0 +1,151 @@
+package generador
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// ================== REINTENTOS ANTE ERRORES TRANSITORIOS ==================
+// sinkConReintentos envuelve un sink SQL (insert, bulk o tvp). Si el dialecto
+// clasifica un error como transitorio, deshace la transacción abierta, espera
+// con backoff exponencial y jitter, abre otra y reenvía los lotes que aún no
+// se habían confirmado. Como el motor rechazó la operación sin confirmar
+// nada, repetir la transacción completa no duplica filas.
+//
+// Las filas y los puntos de control de la transacción en curso quedan en
+// memoria hasta el commit: un bloque de bloqueVentas en Fact_Ventas y la tabla
+// completa en las demás.
+// Con config.Reintentos = 0 no se envuelve el sink.
+type sinkConReintentos struct {
+	sink
+	base   *sinkSQL
+	lotes  [][][]interface{}
+	puntos []puntoControl
+}
+
+const (
+	esperaReintentoBase = time.Second
+	esperaReintentoMax  = 30 * time.Second
+)
+
+// iniciar abre la transacción, reintentando si el motor no está disponible.
+func (s *sinkConReintentos) iniciar() error {
+	if err := s.base.iniciar(); err != nil {
+		return s.recuperar(err, nil)
+	}
+	return nil
+}
+
+func (s *sinkConReintentos) escribir(filas [][]interface{}) error {
+	// El cargador recicla el slice del lote, pero no las filas
+	s.lotes = append(s.lotes, slices.Clone(filas))
+	if err := s.sink.escribir(filas); err != nil {
+		return s.recuperar(err, nil)
+	}
+	return nil
+}
+
+// registrarPunto va siempre después de las filas que cubre, así que al
+// reenviar basta repetirlo detrás de los lotes.
+func (s *sinkConReintentos) registrarPunto(p puntoControl) error {
+	s.puntos = append(s.puntos, p)
+	if err := registrarPunto(s.sink, p); err != nil {
+		return s.recuperar(err, nil)
+	}
+	return nil
+}
+
+// confirmar cierra la transacción y abre la siguiente. Si el commit pasó y
+// falla la apertura, solo se repite la apertura: lo confirmado no se reenvía.
+func (s *sinkConReintentos) confirmar() error {
+	if err := s.cerrar(); err != nil {
+		return err
+	}
+	if err := s.iniciar(); err != nil {
+		return fmt.Errorf("%w: %w", errSiguienteTransaccion, err)
+	}
+	return nil
+}
+
+func (s *sinkConReintentos) cerrar() error {
+	if err := s.sink.cerrar(); err != nil {
+		// Si la conexión se cortó en el commit, este pudo aplicarse. Al
+		// repetirlo, la clave primaria de Control_Checkpoint rechaza el punto
+		// ya registrado; sin puntos de control se duplicarían las filas
+		if conexionPerdida(err) && s.base.hash == "" {
+			return fmt.Errorf("%w (conexión perdida al confirmar; no se reintenta)", err)
+		}
+		if err := s.recuperar(err, s.sink.cerrar); err != nil {
+			return err
+		}
+	}
+	s.lotes, s.puntos = nil, nil
+	return nil
+}
+
+func (s *sinkConReintentos) descartar() {
+	s.lotes, s.puntos = nil, nil
+	s.sink.descartar()
+}
+
+// recuperar repite la transacción mientras el error sea transitorio y queden
+// reintentos; final es el commit pendiente, si el error ocurrió al confirmar.
+func (s *sinkConReintentos) recuperar(err error, final func() error) error {
+	tabla := s.base.tabla.nombre
+	r := s.base.r
+	for intento := 1; r.dialecto.transitorio(err); intento++ {
+		if intento > r.config.Reintentos {
+			return fmt.Errorf("%w (sin éxito tras %d reintentos)", err, r.config.Reintentos)
+		}
+		espera := esperaReintento(intento)
+		log.Printf("🔁 %s: error transitorio (%v); reintento %d/%d en %s",
+			tabla, err, intento, r.config.Reintentos, espera.Round(time.Millisecond))
+		r.rendimiento.sumarReintento(tabla, s.sink.metodo())
+
+		s.sink.descartar()
+		select {
+		case <-time.After(espera):
+		case <-s.base.ctx.Done():
+			return err
+		}
+		err = s.reenviar(final)
+	}
+	return err
+}
+
+// reenviar abre una transacción nueva y repite en ella los lotes y puntos de
+// control pendientes y, si lo hay, el commit.
+func (s *sinkConReintentos) reenviar(final func() error) error {
+	if err := s.base.iniciar(); err != nil {
+		return err
+	}
+	for _, lote := range s.lotes {
+		if err := s.sink.escribir(lote); err != nil {
+			return err
+		}
+	}
+	for _, p := range s.puntos {
+		if err := registrarPunto(s.sink, p); err != nil {
+			return err
+		}
+	}
+	if final != nil {
+		return final()
+	}
+	return nil
+}
+
+// esperaReintento duplica la espera en cada intento hasta esperaReintentoMax
+// y sortea entre la mitad y el total, para que los workers que fallaron a la
+// vez no vuelvan todos juntos.
+func esperaReintento(intento int) time.Duration {
+	espera := esperaReintentoBase
+	for i := 1; i < intento && espera < esperaReintentoMax; i++ {
+		espera *= 2
+	}
+	espera = min(espera, esperaReintentoMax)
+	return espera/2 + rand.N(espera/2+1)
+}
@@ -0,0 +1,55 @@
+package generador
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestEsperaReintento(t *testing.T) {
+	casos := []struct {
+		intento  int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{4, 4 * time.Second, 8 * time.Second},
+		// El tope se mantiene en los intentos siguientes
+		{10, esperaReintentoMax / 2, esperaReintentoMax},
+	}
+	for _, c := range casos {
+		for range 20 {
+			if got := esperaReintento(c.intento); got < c.min || got > c.max {
+				t.Fatalf("esperaReintento(%d) = %s; fuera de [%s, %s]", c.intento, got, c.min, c.max)
+			}
+		}
+	}
+}
+
+func TestConexionPerdida(t *testing.T) {
+	casos := []struct {
+		err     error
+		perdida bool
+	}{
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("lote 3: %w", io.EOF), true},
+		{io.ErrUnexpectedEOF, true},
+		{context.Canceled, false},
+		{fmt.Errorf("%w: %w", context.DeadlineExceeded, io.EOF), false},
+		{errors.New("violación de clave primaria"), false},
+	}
+	for _, c := range casos {
+		if got := conexionPerdida(c.err); got != c.perdida {
+			t.Errorf("conexionPerdida(%v) = %t; se esperaba %t", c.err, got, c.perdida)
+		}
+	}
+	for _, d := range []dialecto{sqlServer{}, postgres{}} {
+		if !d.transitorio(driver.ErrBadConn) {
+			t.Errorf("%s no reintenta la conexión perdida", d.nombre())
+		}
+	}
+}
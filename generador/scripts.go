@@ -0,0 +1,112 @@
+package generador
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/golang-sql/sqlexp"
+)
+
+// ================== EJECUCIÓN DE SCRIPTS SQL ==================
+// Separador de lotes al estilo sqlcmd/SSMS
+var separadorGO = regexp.MustCompile(`(?im)^\s*GO\s*$`)
+
+// EjecutarScript corre un script lote por lote con el dialecto de la
+// corrida; en SQL Server muestra los PRINT y los conjuntos de resultados en el
+// mismo orden en que los emite el servidor.
+func (r *Corrida) EjecutarScript(ctx context.Context, db *sql.DB, nombre, contenido string) error {
+	log.Printf("📜 Ejecutando %s...", nombre)
+	inicio := time.Now()
+
+	for i, lote := range separadorGO.Split(contenido, -1) {
+		if strings.TrimSpace(lote) == "" {
+			continue
+		}
+		if err := r.dialecto.ejecutarLote(ctx, db, lote); err != nil {
+			return fmt.Errorf("%s (lote %d): %w", nombre, i+1, err)
+		}
+	}
+
+	log.Printf("✔ %s ejecutado en %s", nombre, time.Since(inicio).Round(time.Millisecond))
+	return nil
+}
+
+// ejecutarLoteTSQL usa los mensajes de go-mssqldb para intercalar PRINT y resultados.
+func ejecutarLoteTSQL(ctx context.Context, db *sql.DB, lote string) error {
+	retmsg := &sqlexp.ReturnMessage{}
+	rows, err := db.QueryContext(ctx, lote, retmsg)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var errLote error
+	for activo := true; activo; {
+		switch m := retmsg.Message(ctx).(type) {
+		case sqlexp.MsgNotice:
+			fmt.Println(m.Message)
+		case sqlexp.MsgNext:
+			if err := imprimirResultados(rows); err != nil {
+				return err
+			}
+		case sqlexp.MsgNextResultSet:
+			activo = rows.NextResultSet()
+		case sqlexp.MsgError:
+			fmt.Println("❌", m.Error)
+			if errLote == nil {
+				errLote = m.Error
+			}
+		}
+	}
+	if errLote != nil {
+		return errLote
+	}
+	return rows.Err()
+}
+
+func imprimirResultados(rows *sql.Rows) error {
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	valores := make([]interface{}, len(cols))
+	punteros := make([]interface{}, len(cols))
+	for i := range valores {
+		punteros[i] = &valores[i]
+	}
+
+	fmt.Println(strings.Join(cols, " | "))
+	for rows.Next() {
+		if err := rows.Scan(punteros...); err != nil {
+			return err
+		}
+		celdas := make([]string, len(valores))
+		for i, v := range valores {
+			celdas[i] = formatearCelda(v)
+		}
+		fmt.Println(strings.Join(celdas, " | "))
+	}
+	fmt.Println()
+	return nil
+}
+
+func formatearCelda(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(val)
+	case float64:
+		return fmt.Sprintf("%.2f", val)
+	case time.Time:
+		return val.Format("2006-01-02")
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,167 @@
+package generador
+
+// ================== DEFINICIÓN DE TABLAS ==================
+// Columnas que carga el generador, con el tipo declarado en
+// 01_Esquema_Estrella.sql. Las columnas IDENTITY no se incluyen.
+type columna struct {
+	nombre string
+	tipo   string
+}
+
+type tablaSpec struct {
+	nombre   string
+	columnas []columna
+}
+
+func (t tablaSpec) nombresColumnas() []string {
+	nombres := make([]string, len(t.columnas))
+	for i, c := range t.columnas {
+		nombres[i] = c.nombre
+	}
+	return nombres
+}
+
+// columnasEnteras devuelve la posición de las columnas INT y BIGINT, las que
+// entran en la huella de los puntos de control.
+func (t tablaSpec) columnasEnteras() []int {
+	var pos []int
+	for i, c := range t.columnas {
+		if c.tipo == "INT" || c.tipo == "BIGINT" {
+			pos = append(pos, i)
+		}
+	}
+	return pos
+}
+
+var tablaDimTiempo = tablaSpec{"Dim_Tiempo", []columna{
+	{"IDTiempo", "INT"},
+	{"Fecha", "DATE"},
+	{"Anio", "INT"},
+	{"Semestre", "INT"},
+	{"Trimestre", "INT"},
+	{"Mes", "INT"},
+	{"NombreMes", "NVARCHAR(20)"},
+	{"Dia", "INT"},
+	{"DiaSemana", "INT"},
+	{"NombreDiaSemana", "NVARCHAR(15)"},
+	{"NumeroSemana", "INT"},
+	{"EsFinDeSemana", "BIT"},
+	{"EsFeriado", "BIT"},
+	{"TrimestreAnio", "NVARCHAR(10)"},
+	{"NombreFeriado", "NVARCHAR(50)"},
+}}
+
+var tablaDimProducto = tablaSpec{"Dim_Producto", []columna{
+	{"IDProducto", "INT"},
+	{"SKU", "NVARCHAR(50)"},
+	{"NombreProducto", "NVARCHAR(200)"},
+	{"Categoria", "NVARCHAR(100)"},
+	{"Subcategoria", "NVARCHAR(100)"},
+	{"Marca", "NVARCHAR(100)"},
+	{"LineaProducto", "NVARCHAR(100)"},
+	{"Activo", "BIT"},
+}}
+
+var tablaDimCliente = tablaSpec{"Dim_Cliente", []columna{
+	{"IDCliente", "INT"},
+	{"CodigoCliente", "NVARCHAR(20)"},
+	{"NombreCliente", "NVARCHAR(200)"},
+	{"TipoCliente", "NVARCHAR(50)"},
+	{"Segmento", "NVARCHAR(50)"},
+	{"Ciudad", "NVARCHAR(100)"},
+	{"Region", "NVARCHAR(100)"},
+	{"FechaRegistro", "DATE"},
+	{"ClienteActivo", "BIT"},
+}}
+
+var tablaDimSucursal = tablaSpec{"Dim_Sucursal", []columna{
+	{"IDSucursal", "INT"},
+	{"CodigoSucursal", "NVARCHAR(10)"},
+	{"NombreSucursal", "NVARCHAR(150)"},
+	{"Direccion", "NVARCHAR(200)"},
+	{"Ciudad", "NVARCHAR(100)"},
+	{"Region", "NVARCHAR(100)"},
+	{"TipoSucursal", "NVARCHAR(50)"},
+	{"SucursalActiva", "BIT"},
+}}
+
+var tablaDimEmpleado = tablaSpec{"Dim_Empleado", []columna{
+	{"IDEmpleado", "INT"},
+	{"CodigoEmpleado", "NVARCHAR(15)"},
+	{"NombreEmpleado", "NVARCHAR(200)"},
+	{"Cargo", "NVARCHAR(100)"},
+	{"Departamento", "NVARCHAR(100)"},
+	{"IDSucursal", "INT"},
+	{"FechaContratacion", "DATE"},
+	{"EmpleadoActivo", "BIT"},
+}}
+
+var tablaDimCanalVenta = tablaSpec{"Dim_CanalVenta", []columna{
+	{"IDCanal", "INT"},
+	{"CodigoCanal", "NVARCHAR(10)"},
+	{"NombreCanal", "NVARCHAR(50)"},
+	{"TipoCanal", "NVARCHAR(30)"},
+}}
+
+var tablaDimEstadoPedido = tablaSpec{"Dim_EstadoPedido", []columna{
+	{"IDEstado", "INT"},
+	{"CodigoEstado", "NVARCHAR(10)"},
+	{"DescripcionEstado", "NVARCHAR(50)"},
+	{"EsEstadoFinal", "BIT"},
+}}
+
+var tablaFactVentas = tablaSpec{"Fact_Ventas", []columna{
+	{"NumeroPedido", "NVARCHAR(20)"},
+	{"IDTiempoVenta", "INT"},
+	{"IDTiempoPedido", "INT"},
+	{"IDTiempoEntrega", "INT"},
+	{"IDProducto", "INT"},
+	{"IDCliente", "INT"},
+	{"IDSucursal", "INT"},
+	{"IDEmpleado", "INT"},
+	{"IDCanal", "INT"},
+	{"IDEstadoPedido", "INT"},
+	{"CantidadUnidades", "INT"},
+	{"PrecioUnitarioVenta", "DECIMAL(18,2)"},
+	{"CostoUnitario", "DECIMAL(18,2)"},
+	{"DescuentoUnitario", "DECIMAL(18,2)"},
+}}
+
+var tablaFactFinanzas = tablaSpec{"Fact_Finanzas", []columna{
+	{"IDTiempo", "INT"},
+	{"IDSucursal", "INT"},
+	{"VentasTotales", "DECIMAL(18,2)"},
+	{"CostosTotales", "DECIMAL(18,2)"},
+	{"GastosOperativos", "DECIMAL(18,2)"},
+	{"UtilidadBruta", "DECIMAL(18,2)"},
+	{"UtilidadNeta", "DECIMAL(18,2)"},
+	{"MargenBrutoPorcentaje", "DECIMAL(5,2)"},
+}}
+
+var tablaFactSatisfaccion = tablaSpec{"Fact_SatisfaccionCliente", []columna{
+	{"IDTiempo", "INT"},
+	{"IDSucursal", "INT"},
+	{"IDCliente", "INT"},
+	{"IDProducto", "INT"},
+	{"PuntuacionServicio", "INT"},
+	{"PuntuacionProducto", "INT"},
+	{"PuntuacionGeneral", "INT"},
+	{"Recomendaria", "BIT"},
+}}
+
+var tablaFactMetricasWeb = tablaSpec{"Fact_MetricasWeb", []columna{
+	{"IDTiempo", "INT"},
+	{"IDCanal", "INT"},
+	{"SesionesTotales", "INT"},
+	{"UsuariosUnicos", "INT"},
+	{"Conversiones", "INT"},
+	{"TasaConversion", "DECIMAL(5,2)"},
+	{"IngresosDigitales", "DECIMAL(18,2)"},
+}}
+
+// Orden de carga del modelo (dimensiones antes que hechos)
+var tablasModelo = []tablaSpec{
+	tablaDimTiempo, tablaDimProducto, tablaDimCliente, tablaDimSucursal,
+	tablaDimEmpleado, tablaDimCanalVenta, tablaDimEstadoPedido,
+	tablaFactVentas, tablaFactFinanzas, tablaFactSatisfaccion, tablaFactMetricasWeb,
+}
@@ -0,0 +1,173 @@
+package generador
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+)
+
+// ================== CARGA CON TABLE-VALUED PARAMETERS ==================
+// En modo tvp cada lote viaja como un único parámetro de tipo tabla y se
+// inserta con INSERT ... SELECT FROM @filas. Solo SQL Server / Azure SQL.
+// Los tipos de tabla se crean a partir de tablaSpec la primera vez que se usan
+// y se recrean si la base tiene uno con otras columnas, p. ej. de una versión
+// anterior del modelo.
+
+// tipoTablaTVP es el nombre del tipo de tabla definido por el usuario.
+func tipoTablaTVP(tabla tablaSpec) string {
+	return "dbo.TipoCarga_" + tabla.nombre
+}
+
+// ddlTipoTablaTVP arma el CREATE TYPE con las columnas y tipos de la tabla.
+func ddlTipoTablaTVP(tabla tablaSpec) string {
+	columnas := make([]string, len(tabla.columnas))
+	for i, c := range tabla.columnas {
+		columnas[i] = c.nombre + " " + c.tipo + " NOT NULL"
+	}
+	return fmt.Sprintf("CREATE TYPE %s AS TABLE (%s)", tipoTablaTVP(tabla), strings.Join(columnas, ", "))
+}
+
+// asegurarTipoTVP crea el tipo de tabla de tabla o lo recrea si sus columnas,
+// según sys.table_types y sys.columns, no son las de tablaSpec. Cada tipo se
+// verifica una vez por corrida.
+func (r *Corrida) asegurarTipoTVP(ctx context.Context, db *sql.DB, tabla tablaSpec) error {
+	r.muTiposTVP.Lock()
+	defer r.muTiposTVP.Unlock()
+	tipo := tipoTablaTVP(tabla)
+	if r.tiposTVP[tipo] {
+		return nil
+	}
+
+	actuales, err := columnasTipoTVP(ctx, db, tabla)
+	if err != nil {
+		return fmt.Errorf("leyendo %s: %w", tipo, err)
+	}
+	esperadas := make([]string, len(tabla.columnas))
+	for i, c := range tabla.columnas {
+		esperadas[i] = c.nombre + " " + strings.ToUpper(strings.ReplaceAll(c.tipo, " ", ""))
+	}
+	if !slices.Equal(actuales, esperadas) {
+		if len(actuales) > 0 {
+			if _, err := db.ExecContext(ctx, "DROP TYPE "+tipo); err != nil {
+				return fmt.Errorf("quitando %s: %w", tipo, err)
+			}
+			log.Printf("🔄 %s no coincidía con las columnas de %s; se recrea", tipo, tabla.nombre)
+		}
+		if _, err := db.ExecContext(ctx, ddlTipoTablaTVP(tabla)); err != nil {
+			return fmt.Errorf("creando %s: %w", tipo, err)
+		}
+	}
+	if r.tiposTVP == nil {
+		r.tiposTVP = map[string]bool{}
+	}
+	r.tiposTVP[tipo] = true
+	return nil
+}
+
+// columnasTipoTVP describe las columnas del tipo de tabla existente como
+// "Nombre TIPO", con el tipo escrito como en tablaSpec; vacío si no existe.
+func columnasTipoTVP(ctx context.Context, db *sql.DB, tabla tablaSpec) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT c.name, TYPE_NAME(c.user_type_id), c.max_length, c.precision, c.scale
+		FROM sys.table_types tt JOIN sys.columns c ON c.object_id = tt.type_table_object_id
+		WHERE tt.name = @p1 AND SCHEMA_NAME(tt.schema_id) = 'dbo'
+		ORDER BY c.column_id`, strings.TrimPrefix(tipoTablaTVP(tabla), "dbo."))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columnas []string
+	for rows.Next() {
+		var nombre, tipo string
+		var largo int
+		var precision, escala uint8
+		if err := rows.Scan(&nombre, &tipo, &largo, &precision, &escala); err != nil {
+			return nil, err
+		}
+		tipo = strings.ToUpper(tipo)
+		switch tipo {
+		case "NVARCHAR":
+			if largo < 0 {
+				tipo += "(MAX)"
+			} else {
+				tipo += fmt.Sprintf("(%d)", largo/2)
+			}
+		case "DECIMAL":
+			tipo += fmt.Sprintf("(%d,%d)", precision, escala)
+		}
+		columnas = append(columnas, nombre+" "+tipo)
+	}
+	return columnas, rows.Err()
+}
+
+// tipoGoTVP es el tipo Go con el que go-mssqldb describe cada columna del
+// TVP; el servidor convierte al tipo declarado en el tipo de tabla.
+func tipoGoTVP(tipo string) (reflect.Type, error) {
+	switch {
+	case tipo == "INT", tipo == "BIGINT":
+		return reflect.TypeOf(int64(0)), nil
+	case tipo == "BIT":
+		return reflect.TypeOf(false), nil
+	case tipo == "DATE":
+		return reflect.TypeOf(time.Time{}), nil
+	case strings.HasPrefix(tipo, "DECIMAL"):
+		return reflect.TypeOf(float64(0)), nil
+	case strings.HasPrefix(tipo, "NVARCHAR"):
+		return reflect.TypeOf(""), nil
+	}
+	return nil, fmt.Errorf("tipo %s sin equivalente para TVP", tipo)
+}
+
+// sinkTVP convierte cada lote en un slice de structs generados con
+// reflect.StructOf (un campo por columna) y lo envía como TVP.
+type sinkTVP struct {
+	*sinkSQL
+	fila      reflect.Type
+	sentencia string
+}
+
+func abrirTVP(ctx context.Context, base *sinkSQL) (sink, error) {
+	campos := make([]reflect.StructField, len(base.tabla.columnas))
+	for i, c := range base.tabla.columnas {
+		t, err := tipoGoTVP(c.tipo)
+		if err != nil {
+			return nil, fmt.Errorf("columna %s.%s: %w", base.tabla.nombre, c.nombre, err)
+		}
+		campos[i] = reflect.StructField{Name: c.nombre, Type: t}
+	}
+
+	if err := base.r.asegurarTipoTVP(ctx, base.db, base.tabla); err != nil {
+		return nil, err
+	}
+
+	columnas := strings.Join(base.columnas, ", ")
+	return &sinkTVP{
+		sinkSQL: base,
+		fila:    reflect.StructOf(campos),
+		sentencia: fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM @filas",
+			base.tabla.nombre, columnas, columnas),
+	}, nil
+}
+
+func (s *sinkTVP) metodo() string { return modoTVP }
+
+func (s *sinkTVP) escribir(filas [][]interface{}) error {
+	valores := reflect.MakeSlice(reflect.SliceOf(s.fila), len(filas), len(filas))
+	for i, fila := range filas {
+		registro := valores.Index(i)
+		for j, v := range fila {
+			campo := registro.Field(j)
+			campo.Set(reflect.ValueOf(v).Convert(campo.Type()))
+		}
+	}
+	tvp := mssql.TVP{TypeName: tipoTablaTVP(s.tabla), Value: valores.Interface()}
+	_, err := s.tx.ExecContext(s.ctx, s.sentencia, sql.Named("filas", tvp))
+	return err
+}
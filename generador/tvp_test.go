@@ -0,0 +1,44 @@
+package generador
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDDLTipoTablaTVP(t *testing.T) {
+	esperado := "CREATE TYPE dbo.TipoCarga_Dim_CanalVenta AS TABLE (IDCanal INT NOT NULL, " +
+		"CodigoCanal NVARCHAR(10) NOT NULL, NombreCanal NVARCHAR(50) NOT NULL, TipoCanal NVARCHAR(30) NOT NULL)"
+	if got := ddlTipoTablaTVP(tablaDimCanalVenta); got != esperado {
+		t.Errorf("ddlTipoTablaTVP = %s\nse esperaba %s", got, esperado)
+	}
+}
+
+func TestTipoGoTVP(t *testing.T) {
+	casos := map[string]reflect.Type{
+		"INT":           reflect.TypeOf(int64(0)),
+		"BIT":           reflect.TypeOf(false),
+		"DATE":          reflect.TypeOf(time.Time{}),
+		"DECIMAL(18,2)": reflect.TypeOf(float64(0)),
+		"NVARCHAR(50)":  reflect.TypeOf(""),
+	}
+	for tipo, esperado := range casos {
+		if got, err := tipoGoTVP(tipo); err != nil || got != esperado {
+			t.Errorf("tipoGoTVP(%s) = %v, %v; se esperaba %v", tipo, got, err, esperado)
+		}
+	}
+	if _, err := tipoGoTVP("XML"); err == nil {
+		t.Error("tipoGoTVP aceptó un tipo sin equivalente")
+	}
+}
+
+// Todas las columnas del modelo deben poder viajar en un TVP.
+func TestTablasModeloAdmitenTVP(t *testing.T) {
+	for _, tabla := range tablasModelo {
+		for _, c := range tabla.columnas {
+			if _, err := tipoGoTVP(c.tipo); err != nil {
+				t.Errorf("%s.%s: %v", tabla.nombre, c.nombre, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	_ "embed"
+)
+
+// ================== SCRIPTS SQL EMBEBIDOS ==================
+//
+//go:embed 01_Esquema_Estrella.sql
+var scriptEsquema string
+
+//go:embed 03_Consultas_KPIs.sql
+var scriptKPIs string
+
+//go:embed 04_Validacion_Datos.sql
+var scriptValidacion string
+
+//go:embed 05_Crear_Indices.sql
+var scriptIndices string
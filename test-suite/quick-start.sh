@@ -263,7 +263,7 @@ case $option in
         # Ejecutar generación de datos
         echo ""
         echo -e "${BLUE}💾 Generando datos (SCALE_FACTOR=0.001)...${NC}"
-        (cd .. && SCALE_FACTOR=0.001 go run 02_Generacion_Datos.go)
+        (cd .. && SCALE_FACTOR=0.001 go run . generate)
         
         # Ejecutar tests
         echo ""
@@ -298,7 +298,7 @@ case $option in
         
         echo ""
         echo -e "${BLUE}💾 Generando datos (SCALE_FACTOR=0.01)...${NC}"
-        (cd .. && SCALE_FACTOR=0.01 go run 02_Generacion_Datos.go)
+        (cd .. && SCALE_FACTOR=0.01 go run . generate)
         
         echo ""
         echo -e "${BLUE}🧪 Ejecutando validaciones...${NC}"
@@ -337,7 +337,7 @@ case $option in
             
             echo ""
             echo -e "${BLUE}💾 Generando datos (SCALE_FACTOR=1.0)...${NC}"
-            (cd .. && SCALE_FACTOR=1.0 go run 02_Generacion_Datos.go)
+            (cd .. && SCALE_FACTOR=1.0 go run . generate)
             
             echo ""
             echo -e "${BLUE}🧪 Ejecutando validaciones...${NC}"
@@ -33,6 +33,9 @@ IF OBJECT_ID('Dim_Sucursal', 'U') IS NOT NULL DROP TABLE Dim_Sucursal;
 IF OBJECT_ID('Dim_Cliente', 'U') IS NOT NULL DROP TABLE Dim_Cliente;
 IF OBJECT_ID('Dim_Producto', 'U') IS NOT NULL DROP TABLE Dim_Producto;
 IF OBJECT_ID('Dim_Tiempo', 'U') IS NOT NULL DROP TABLE Dim_Tiempo;
+IF OBJECT_ID('Control_Ejecucion', 'U') IS NOT NULL DROP TABLE Control_Ejecucion;
+IF OBJECT_ID('Control_Checkpoint', 'U') IS NOT NULL DROP TABLE Control_Checkpoint;
+IF OBJECT_ID('Control_Destino', 'U') IS NOT NULL DROP TABLE Control_Destino;
 
 PRINT '✅ Tablas limpiadas';
 GO
@@ -56,7 +59,8 @@ CREATE TABLE Dim_Tiempo (
     NumeroSemana INT NOT NULL,
     EsFinDeSemana BIT NOT NULL,
     EsFeriado BIT NOT NULL,
-    TrimestreAnio VARCHAR(10) NOT NULL
+    TrimestreAnio VARCHAR(10) NOT NULL,
+    NombreFeriado VARCHAR(50) NOT NULL DEFAULT ''
 );
 
 CREATE INDEX idx_tiempo_fecha ON Dim_Tiempo(Fecha);
@@ -310,6 +314,45 @@ CREATE INDEX idx_metricasweb_canal ON Fact_MetricasWeb(IDCanal);
 PRINT '✅ Fact_MetricasWeb creada';
 GO
 
+-- =============================================================================
+-- CONTROL DEL GENERADOR
+-- =============================================================================
+
+-- Control_Ejecucion: perfil y configuración de cada corrida del generador
+CREATE TABLE Control_Ejecucion (
+    IDEjecucion BIGINT IDENTITY(1,1) PRIMARY KEY,
+    Perfil NVARCHAR(100) NOT NULL,
+    ConfigJSON NVARCHAR(MAX) NOT NULL,
+    FechaInicio DATETIME2 NOT NULL,
+    FechaFin DATETIME2 NULL,
+    Estado NVARCHAR(20) NOT NULL
+);
+PRINT '✅ Control_Ejecucion creada';
+GO
+
+-- Control_Checkpoint: un punto de control por commit de la carga (-resume)
+CREATE TABLE Control_Checkpoint (
+    HashConfig NVARCHAR(64) NOT NULL,
+    Tabla NVARCHAR(50) NOT NULL,
+    Bloque INT NOT NULL,
+    UltimoNumeroPedido NVARCHAR(20) NULL,
+    EstadoRNG NVARCHAR(100) NULL,
+    Filas INT NOT NULL,
+    Huella NVARCHAR(1000) NULL,
+    FechaConfirmacion DATETIME2 NOT NULL,
+    PRIMARY KEY (HashConfig, Tabla, Bloque)
+);
+PRINT '✅ Control_Checkpoint creada';
+GO
+
+-- Control_Destino: marca de destino protegido
+CREATE TABLE Control_Destino (
+    Nombre NVARCHAR(100) NOT NULL PRIMARY KEY,
+    FechaMarca DATETIME2 NOT NULL
+);
+PRINT '✅ Control_Destino creada';
+GO
+
 -- =============================================================================
 -- VISTAS ANALÍTICAS PARA TESTING
 -- =============================================================================
@@ -384,7 +427,7 @@ PRINT '  • 2 Vistas Analíticas';
 PRINT '  • 1 Procedimiento Almacenado';
 PRINT '';
 PRINT 'Próximos pasos:';
-PRINT '  1. Ejecutar: go run 02_Generacion_Datos.go';
+PRINT '  1. Ejecutar: go run . generate';
 PRINT '  2. Validar: go run test_suite.go';
 PRINT '';
 GO
\ No newline at end of file
@@ -3,19 +3,26 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	_ "github.com/denisenkom/go-mssqldb"
 	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 // ================== CONFIGURACIÓN DE TESTING ==================
 type TestConfig struct {
 	Mode             string // "local" o "staging"
+	Dialect          string // "sqlserver", "postgres" o "sqlite" (DB_DIALECT)
 	ScaleFactor      float64 // 0.01 = 1% de datos reales
 	EnableValidation bool
 	EnableProfiling  bool
@@ -24,12 +31,149 @@ type TestConfig struct {
 
 var testConfig = TestConfig{
 	Mode:             "local",
+	Dialect:          "sqlserver",
 	ScaleFactor:      0.01, // 10,000 registros en lugar de 1M
 	EnableValidation: true,
 	EnableProfiling:  true,
 	MaxExecutionTime: 10 * time.Minute,
 }
 
+// ================== VOLÚMENES ESPERADOS ==================
+// Volúmenes base de la configuración compilada de 02_Generacion_Datos.go
+// (perfil 1M). Solo se usan, escalados con SCALE_FACTOR, si la base no
+// registra una carga completa en Control_Ejecucion. Sucursales, canales y
+// estados no se escalan en el generador.
+var volumenesBase = map[string]int{
+	"Fact_Ventas":              894_083,
+	"Fact_SatisfaccionCliente": 50_000,
+	"Dim_Producto":             2_000,
+	"Dim_Cliente":              50_000,
+	"Dim_Empleado":             2_000,
+}
+
+const sucursalesBase = 20
+
+// escalar replica la fórmula del generador: round(n*factor), mínimo 1.
+func escalar(n int, factor float64) int {
+	v := int(math.Round(float64(n) * factor))
+	if v < 1 {
+		return 1
+	}
+	return v
+}
+
+// volumenesEjecucion son los volúmenes de la última ejecución completada,
+// ya escalados por el generador; nil si no hay ninguna.
+var volumenesEjecucion map[string]int
+
+// configEjecucion es la parte de Control_Ejecucion.ConfigJSON que fija los
+// volúmenes del dataset.
+type configEjecucion struct {
+	Perfil              string  `json:"perfil"`
+	ScaleFactor         float64 `json:"scale_factor"`
+	VentasRecords       int     `json:"ventas_records"`
+	SatisfaccionRecords int     `json:"satisfaccion_records"`
+	DimProductos        int     `json:"dim_productos"`
+	DimClientes         int     `json:"dim_clientes"`
+	DimSucursales       int     `json:"dim_sucursales"`
+	DimEmpleados        int     `json:"dim_empleados"`
+}
+
+// cargarVolumenesEjecucion toma los volúmenes esperados de la configuración
+// con que se lanzó la última ejecución COMPLETADA, así la validación sirve
+// para cualquier perfil (10M, dev, uno propio) y factor de escala. Si la base
+// no tiene Control_Ejecucion o ninguna carga terminó, se siguen usando los
+// base escalados.
+func cargarVolumenesEjecucion(ctx context.Context, db *sql.DB) {
+	var ultima int64
+	var estadoUltima string
+	err := db.QueryRowContext(ctx, adaptarConsulta(`SELECT TOP 1 IDEjecucion, Estado
+		FROM Control_Ejecucion ORDER BY IDEjecucion DESC`)).Scan(&ultima, &estadoUltima)
+	if err == nil && estadoUltima != "COMPLETADA" {
+		log.Printf("⚠️  La última ejecución (#%d) quedó %s: la base puede tener una carga parcial", ultima, estadoUltima)
+	}
+
+	var id int64
+	var configJSON string
+	err = db.QueryRowContext(ctx, adaptarConsulta(`SELECT TOP 1 IDEjecucion, ConfigJSON
+		FROM Control_Ejecucion WHERE Estado = 'COMPLETADA' ORDER BY IDEjecucion DESC`)).Scan(&id, &configJSON)
+	if err != nil {
+		log.Printf("⚠️  Sin ejecuciones completadas en Control_Ejecucion (%v); volúmenes del perfil 1M a escala %g",
+			err, testConfig.ScaleFactor)
+		return
+	}
+
+	var cfg configEjecucion
+	if err := json.Unmarshal([]byte(configJSON), &cfg); err != nil || cfg.VentasRecords <= 0 {
+		log.Printf("⚠️  ConfigJSON de la ejecución #%d ilegible; volúmenes del perfil 1M a escala %g",
+			id, testConfig.ScaleFactor)
+		return
+	}
+	volumenesEjecucion = map[string]int{
+		"Fact_Ventas":              cfg.VentasRecords,
+		"Fact_SatisfaccionCliente": cfg.SatisfaccionRecords,
+		"Dim_Producto":             cfg.DimProductos,
+		"Dim_Cliente":              cfg.DimClientes,
+		"Dim_Sucursal":             cfg.DimSucursales,
+		"Dim_Empleado":             cfg.DimEmpleados,
+	}
+	if cfg.ScaleFactor > 0 {
+		testConfig.ScaleFactor = cfg.ScaleFactor
+	}
+	log.Printf("📋 Volúmenes esperados de la ejecución #%d (perfil %s, escala %g)",
+		id, cfg.Perfil, testConfig.ScaleFactor)
+}
+
+func volumenEsperado(tabla string) int {
+	if v, ok := volumenesEjecucion[tabla]; ok {
+		return v
+	}
+	if tabla == "Dim_Sucursal" {
+		return sucursalesBase
+	}
+	return escalar(volumenesBase[tabla], testConfig.ScaleFactor)
+}
+
+// ================== DIALECTO ==================
+var reTop = regexp.MustCompile(`(?is)^(\s*SELECT\s+)TOP\s+(\d+)\s+(.*)$`)
+
+// adaptarConsulta traduce las consultas escritas en T-SQL al dialecto activo:
+// fuera de SQL Server, "SELECT TOP n ..." se convierte en "... LIMIT n".
+func adaptarConsulta(query string) string {
+	if testConfig.Dialect == "sqlserver" {
+		return query
+	}
+	if m := reTop.FindStringSubmatch(query); m != nil {
+		return m[1] + strings.TrimSpace(m[3]) + " LIMIT " + m[2]
+	}
+	return query
+}
+
+// abrirConexion usa las variables AZURE_SQL_* en SQL Server, POSTGRES_URL
+// (o las variables PG* estándar) en PostgreSQL y SQLITE_PATH en SQLite.
+func abrirConexion() (*sql.DB, error) {
+	switch testConfig.Dialect {
+	case "postgres":
+		return sql.Open("postgres", os.Getenv("POSTGRES_URL"))
+	case "sqlite":
+		ruta := os.Getenv("SQLITE_PATH")
+		if ruta == "" {
+			ruta = "../datawarehouse.db" // el generador lo crea en la raíz del repo
+		}
+		return sql.Open("sqlite3", "file:"+ruta+"?mode=ro")
+	}
+
+	server := os.Getenv("AZURE_SQL_SERVER")
+	port := os.Getenv("AZURE_SQL_PORT")
+	user := os.Getenv("AZURE_SQL_USER")
+	password := os.Getenv("AZURE_SQL_PASSWORD")
+	database := os.Getenv("AZURE_SQL_DATABASE")
+
+	connString := fmt.Sprintf("server=%s;port=%s;user id=%s;password=%s;database=%s;encrypt=disable",
+		server, port, user, password, database)
+	return sql.Open("sqlserver", connString)
+}
+
 // ================== SISTEMA DE VALIDACIÓN ==================
 type ValidationResult struct {
 	TestName    string
@@ -110,7 +254,7 @@ func (ts *TestSuite) ValidateReferentialIntegrity(ctx context.Context) {
 	for _, test := range tests {
 		start := time.Now()
 		var count int
-		err := ts.db.QueryRowContext(ctx, test.query).Scan(&count)
+		err := ts.db.QueryRowContext(ctx, adaptarConsulta(test.query)).Scan(&count)
 		elapsed := time.Since(start).Milliseconds()
 
 		result := ValidationResult{
@@ -182,7 +326,7 @@ func (ts *TestSuite) ValidateDataQuality(ctx context.Context) {
 		{
 			name: "Clientes activos representan >80%",
 			query: `SELECT 
-					(SELECT COUNT(*) FROM Dim_Cliente WHERE ClienteActivo = 0) * 100.0 / 
+					(SELECT COUNT(*) FROM Dim_Cliente WHERE CAST(ClienteActivo AS INT) = 0) * 100.0 / 
 					(SELECT COUNT(*) FROM Dim_Cliente)`,
 			threshold: 20,
 			message: "Demasiados clientes inactivos",
@@ -190,7 +334,7 @@ func (ts *TestSuite) ValidateDataQuality(ctx context.Context) {
 		{
 			name: "Productos activos representan >70%",
 			query: `SELECT 
-					(SELECT COUNT(*) FROM Dim_Producto WHERE Activo = 0) * 100.0 / 
+					(SELECT COUNT(*) FROM Dim_Producto WHERE CAST(Activo AS INT) = 0) * 100.0 / 
 					(SELECT COUNT(*) FROM Dim_Producto)`,
 			threshold: 30,
 			message: "Demasiados productos inactivos",
@@ -207,7 +351,7 @@ func (ts *TestSuite) ValidateDataQuality(ctx context.Context) {
 	for _, test := range tests {
 		start := time.Now()
 		var value float64
-		err := ts.db.QueryRowContext(ctx, test.query).Scan(&value)
+		err := ts.db.QueryRowContext(ctx, adaptarConsulta(test.query)).Scan(&value)
 		elapsed := time.Since(start).Milliseconds()
 
 		result := ValidationResult{
@@ -292,7 +436,7 @@ func (ts *TestSuite) ValidateDataDistribution(ctx context.Context) {
 
 	for _, q := range queries {
 		start := time.Now()
-		rows, err := ts.db.QueryContext(ctx, q.query)
+		rows, err := ts.db.QueryContext(ctx, adaptarConsulta(q.query))
 		elapsed := time.Since(start).Milliseconds()
 
 		result := ValidationResult{
@@ -341,10 +485,65 @@ func (ts *TestSuite) ValidateDataDistribution(ctx context.Context) {
 	}
 }
 
+// ================== TESTS DE VOLUMEN ESCALADO ==================
+func (ts *TestSuite) ValidateVolumes(ctx context.Context) {
+	log.Printf("\n📏 VALIDANDO VOLÚMENES (SCALE_FACTOR=%g)...", testConfig.ScaleFactor)
+
+	tests := []struct {
+		tabla         string
+		esperado      int
+		toleranciaPct float64 // Fact_Ventas omite fechas fuera de Dim_Tiempo
+	}{
+		{"Dim_Producto", volumenEsperado("Dim_Producto"), 0},
+		{"Dim_Cliente", volumenEsperado("Dim_Cliente"), 0},
+		{"Dim_Empleado", volumenEsperado("Dim_Empleado"), 0},
+		{"Dim_Sucursal", volumenEsperado("Dim_Sucursal"), 0},
+		{"Fact_Ventas", volumenEsperado("Fact_Ventas"), 1},
+		{"Fact_SatisfaccionCliente", volumenEsperado("Fact_SatisfaccionCliente"), 1},
+	}
+
+	for _, test := range tests {
+		start := time.Now()
+		var count int
+		err := ts.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+test.tabla).Scan(&count)
+		elapsed := time.Since(start).Milliseconds()
+
+		result := ValidationResult{
+			TestName:    "Volumen " + test.tabla,
+			Expected:    test.esperado,
+			Actual:      count,
+			ExecutionMS: elapsed,
+		}
+
+		desviacion := math.Abs(float64(count-test.esperado)) * 100 / float64(test.esperado)
+		if err != nil {
+			result.Status = "FAIL"
+			result.Message = fmt.Sprintf("Error: %v", err)
+		} else if desviacion > test.toleranciaPct {
+			result.Status = "WARNING"
+			result.Message = fmt.Sprintf("%d registros, esperados %d (desviación %.2f%%)",
+				count, test.esperado, desviacion)
+		} else {
+			result.Status = "PASS"
+			result.Message = fmt.Sprintf("%d registros (esperados %d)", count, test.esperado)
+		}
+
+		ts.results = append(ts.results, result)
+		ts.printResult(result)
+	}
+}
+
 // ================== TESTS DE RENDIMIENTO ==================
 func (ts *TestSuite) ValidatePerformance(ctx context.Context) {
 	log.Println("\n⚡ VALIDANDO RENDIMIENTO DE QUERIES...")
 
+	// Meses cubiertos por Dim_Tiempo: dependen de la fecha ancla del dataset
+	var mesesDataset int
+	if err := ts.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM (SELECT DISTINCT Anio, Mes FROM Dim_Tiempo) m`).Scan(&mesesDataset); err != nil {
+		log.Printf("⚠️  No se pudieron contar los meses de Dim_Tiempo: %v", err)
+	}
+
 	queries := []struct {
 		name          string
 		query         string
@@ -361,7 +560,7 @@ func (ts *TestSuite) ValidatePerformance(ctx context.Context) {
 					GROUP BY dt.Anio, dt.Mes
 					ORDER BY dt.Anio, dt.Mes`,
 			maxTimeMS: 5000,
-			expectedRows: 36, // 3 años
+			expectedRows: min(mesesDataset, volumenEsperado("Fact_Ventas")),
 		},
 		{
 			name: "Query Top Productos",
@@ -373,7 +572,7 @@ func (ts *TestSuite) ValidatePerformance(ctx context.Context) {
 					GROUP BY dp.NombreProducto
 					ORDER BY Ventas DESC`,
 			maxTimeMS: 3000,
-			expectedRows: 10,
+			expectedRows: min(10, volumenEsperado("Dim_Producto")),
 		},
 		{
 			name: "Query Métricas por Sucursal",
@@ -386,13 +585,13 @@ func (ts *TestSuite) ValidatePerformance(ctx context.Context) {
 					GROUP BY ds.NombreSucursal, ds.Ciudad
 					ORDER BY TotalVentas DESC`,
 			maxTimeMS: 4000,
-			expectedRows: 20,
+			expectedRows: volumenEsperado("Dim_Sucursal"),
 		},
 	}
 
 	for _, q := range queries {
 		start := time.Now()
-		rows, err := ts.db.QueryContext(ctx, q.query)
+		rows, err := ts.db.QueryContext(ctx, adaptarConsulta(q.query))
 		elapsed := time.Since(start).Milliseconds()
 
 		result := ValidationResult{
@@ -415,6 +614,10 @@ func (ts *TestSuite) ValidatePerformance(ctx context.Context) {
 			if elapsed > q.maxTimeMS {
 				result.Status = "WARNING"
 				result.Message = fmt.Sprintf("Query lento: %dms (límite: %dms)", elapsed, q.maxTimeMS)
+			} else if rowCount != q.expectedRows {
+				result.Status = "WARNING"
+				result.Message = fmt.Sprintf("Filas inesperadas: %d (esperadas: %d a escala %.3f)",
+					rowCount, q.expectedRows, testConfig.ScaleFactor)
 			} else {
 				result.Status = "PASS"
 				result.Message = fmt.Sprintf("Performance OK: %dms (%d filas)", elapsed, rowCount)
@@ -527,16 +730,24 @@ func main() {
 		godotenv.Load()
 	}
 
-	server := os.Getenv("AZURE_SQL_SERVER")
-	port := os.Getenv("AZURE_SQL_PORT")
-	user := os.Getenv("AZURE_SQL_USER")
-	password := os.Getenv("AZURE_SQL_PASSWORD")
-	database := os.Getenv("AZURE_SQL_DATABASE")
+	if v := os.Getenv("SCALE_FACTOR"); v != "" {
+		factor, err := strconv.ParseFloat(v, 64)
+		if err != nil || factor <= 0 {
+			log.Fatalf("❌ SCALE_FACTOR inválido: %q", v)
+		}
+		testConfig.ScaleFactor = factor
+	}
 
-	connString := fmt.Sprintf("server=%s;port=%s;user id=%s;password=%s;database=%s;encrypt=disable",
-		server, port, user, password, database)
+	if v := os.Getenv("DB_DIALECT"); v != "" {
+		testConfig.Dialect = strings.ToLower(v)
+	}
+	switch testConfig.Dialect {
+	case "sqlserver", "postgres", "sqlite":
+	default:
+		log.Fatalf("❌ DB_DIALECT no soportado: %q", testConfig.Dialect)
+	}
 
-	db, err := sql.Open("sqlserver", connString)
+	db, err := abrirConexion()
 	if err != nil {
 		log.Fatalf("❌ Error de conexión: %v", err)
 	}
@@ -548,10 +759,11 @@ func main() {
 	if err := db.PingContext(ctx); err != nil {
 		log.Fatalf("❌ No se pudo conectar: %v", err)
 	}
+	cargarVolumenesEjecucion(ctx, db)
 
 	log.Println("🧪 INICIANDO SUITE DE TESTING")
-	log.Printf("📊 Modo: %s | Factor de escala: %.2f%%\n", 
-		testConfig.Mode, testConfig.ScaleFactor*100)
+	log.Printf("📊 Modo: %s | Motor: %s | Factor de escala: %.2f%%\n", 
+		testConfig.Mode, testConfig.Dialect, testConfig.ScaleFactor*100)
 
 	suite := NewTestSuite(db)
 	
@@ -559,6 +771,7 @@ func main() {
 	suite.ValidateReferentialIntegrity(ctx)
 	suite.ValidateDataQuality(ctx)
 	suite.ValidateDataDistribution(ctx)
+	suite.ValidateVolumes(ctx)
 	suite.ValidatePerformance(ctx)
 	
 	// Generar reporte final